@@ -0,0 +1,61 @@
+// Command asynclog-convert renders binary asynclog files as text.
+//
+// Usage:
+//
+//	asynclog-convert [-json] [file ...]
+//
+// With no files it reads standard input. The -json flag renders each record
+// as a JSON line instead of the default text layout.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simp-lee/asynclog"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "render records as JSON lines")
+	flag.Parse()
+
+	formatter := asynclog.LineFormatter(asynclog.FormatLineAsText)
+	if *asJSON {
+		formatter = asynclog.FormatLineAsJSON
+	}
+
+	if flag.NArg() == 0 {
+		if err := asynclog.ConvertBinaryLog(os.Stdin, os.Stdout, formatter); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	for _, name := range flag.Args() {
+		if err := convertFile(name, os.Stdout, formatter); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// convertFile renders the binary records of a single file.
+func convertFile(name string, w io.Writer, formatter asynclog.LineFormatter) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := asynclog.ConvertBinaryLog(file, w, formatter); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// fail prints the error and exits with a non-zero status.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "asynclog-convert:", err)
+	os.Exit(1)
+}
@@ -2,7 +2,10 @@ module github.com/simp-lee/asynclog
 
 go 1.21.5
 
-require github.com/fatih/color v1.16.0
+require (
+	github.com/fatih/color v1.16.0
+	golang.org/x/term v0.14.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
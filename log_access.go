@@ -0,0 +1,78 @@
+package asynclog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// clfTimeFormat is the timestamp layout used by the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// FormatCommonLog formats an HTTP access log message in the Common Log Format:
+//
+//	remote_addr - user [time] "method path protocol" status bytes
+//
+// It reads the params remote_addr, user, method, path, protocol, status and bytes.
+// Missing values are rendered as "-", and protocol defaults to HTTP/1.1.
+func FormatCommonLog(m LogMessage) string {
+	request := fmt.Sprintf("%s %s %s",
+		clfParam(m.Params, "method", "-"),
+		clfParam(m.Params, "path", "-"),
+		clfParam(m.Params, "protocol", "HTTP/1.1"))
+
+	return fmt.Sprintf("%s - %s [%s] \"%s\" %s %s",
+		clfParam(m.Params, "remote_addr", "-"),
+		clfParam(m.Params, "user", "-"),
+		m.Time.Format(clfTimeFormat),
+		clfEscape(request),
+		clfParam(m.Params, "status", "-"),
+		clfBytes(m.Params))
+}
+
+// FormatCombinedLog formats an HTTP access log message in the Apache Combined Log Format,
+// which is the Common Log Format followed by the quoted referer and user_agent params.
+func FormatCombinedLog(m LogMessage) string {
+	return fmt.Sprintf("%s \"%s\" \"%s\"",
+		FormatCommonLog(m),
+		clfEscape(clfParam(m.Params, "referer", "-")),
+		clfEscape(clfParam(m.Params, "user_agent", "-")))
+}
+
+// clfParam returns the string form of a param, or fallback if it is missing or empty.
+func clfParam(params map[string]interface{}, key, fallback string) string {
+	value, ok := params[key]
+	if !ok || value == nil {
+		return fallback
+	}
+	text := fmt.Sprint(value)
+	if text == "" {
+		return fallback
+	}
+	return text
+}
+
+// clfBytes returns the response size, using "-" when no bytes were sent.
+func clfBytes(params map[string]interface{}) string {
+	text := clfParam(params, "bytes", "-")
+	if text == "0" {
+		return "-"
+	}
+	return text
+}
+
+// clfEscape escapes characters that would break a quoted CLF field.
+func clfEscape(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			builder.WriteString(fmt.Sprintf("\\x%02x", r))
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,60 @@
+package asynclog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatCommonLog(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 30, 45, 0, time.FixedZone("", 2*60*60))
+	full := map[string]interface{}{
+		"remote_addr": "10.0.0.1", "user": "alice", "method": "GET", "path": "/index.html",
+		"protocol": "HTTP/2.0", "status": 200, "bytes": 1024,
+		"referer": "https://example.com/", "user_agent": "curl/8.0",
+	}
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		formatter LineFormatter
+		want      string
+	}{
+		{"full common", full, FormatCommonLog,
+			`10.0.0.1 - alice [01/Jun/2024:12:30:45 +0200] "GET /index.html HTTP/2.0" 200 1024`},
+		{"full combined", full, FormatCombinedLog,
+			`10.0.0.1 - alice [01/Jun/2024:12:30:45 +0200] "GET /index.html HTTP/2.0" 200 1024 "https://example.com/" "curl/8.0"`},
+		{"missing params", nil, FormatCombinedLog,
+			`- - - [01/Jun/2024:12:30:45 +0200] "- - HTTP/1.1" - - "-" "-"`},
+		{"empty and nil params", map[string]interface{}{"user": "", "referer": nil}, FormatCombinedLog,
+			`- - - [01/Jun/2024:12:30:45 +0200] "- - HTTP/1.1" - - "-" "-"`},
+		{"no bytes sent", map[string]interface{}{"method": "HEAD", "path": "/", "status": 304, "bytes": 0}, FormatCommonLog,
+			`- - - [01/Jun/2024:12:30:45 +0200] "HEAD / HTTP/1.1" 304 -`},
+		{"escaped quotes and backslashes", map[string]interface{}{"method": "GET", "path": `/a"b\c`,
+			"user_agent": `say "hi"`}, FormatCombinedLog,
+			`- - - [01/Jun/2024:12:30:45 +0200] "GET /a\"b\\c HTTP/1.1" - - "-" "say \"hi\""`},
+		{"escaped control characters", map[string]interface{}{"method": "GET", "path": "/a\nb\x7f",
+			"referer": "x\ty"}, FormatCombinedLog,
+			`- - - [01/Jun/2024:12:30:45 +0200] "GET /a\x0ab\x7f HTTP/1.1" - - "x\x09y" "-"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.formatter(LogMessage{Level: LogLevelInfo, Time: at, Params: tt.params})
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessLogFile(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 30, 45, 0, time.UTC)
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetFileFormatter(FormatCommonLog))
+
+	l.Info("request", SetTimestamp(at), SetLogParams(map[string]interface{}{
+		"remote_addr": "10.0.0.1", "method": "GET", "path": "/", "status": 200, "bytes": 5,
+	}))
+
+	want := `10.0.0.1 - - [01/Jun/2024:12:30:45 +0000] "GET / HTTP/1.1" 200 5` + "\n"
+	if got := readFile(t, file); got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
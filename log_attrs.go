@@ -0,0 +1,63 @@
+package asynclog
+
+// BadKey is the param key of attribute values without a valid key.
+const BadKey = "!BADKEY"
+
+// SetAttrs sets params from alternating key-value pairs, like log/slog:
+//
+//	SetAttrs("user_id", 123, "action", "login")
+//
+// A value whose key is not a string, and a final key without a value,
+// are logged under BadKey rather than being dropped.
+func SetAttrs(args ...interface{}) LogOption {
+	return func(m *LogMessage) {
+		// Walk the pairs without consuming args, so the option can be reused
+		for i := 0; i < len(args); {
+			key, ok := args[i].(string)
+			switch {
+			case !ok:
+				m.setParam(BadKey, args[i])
+				i++
+			case i == len(args)-1:
+				m.setParam(BadKey, key)
+				i++
+			default:
+				m.setParam(key, args[i+1])
+				i += 2
+			}
+		}
+	}
+}
+
+// TraceAttrs logs a message at the Trace level with params from key-value pairs.
+func (l *Logger) TraceAttrs(message string, args ...interface{}) {
+	l.log(LogLevelTrace, message, SetAttrs(args...))
+}
+
+// DebugAttrs logs a message at the Debug level with params from key-value pairs.
+func (l *Logger) DebugAttrs(message string, args ...interface{}) {
+	l.log(LogLevelDebug, message, SetAttrs(args...))
+}
+
+// InfoAttrs logs a message at the Info level with params from key-value pairs,
+// e.g. logger.InfoAttrs("user action", "user_id", 123, "action", "login").
+func (l *Logger) InfoAttrs(message string, args ...interface{}) {
+	l.log(LogLevelInfo, message, SetAttrs(args...))
+}
+
+// WarningAttrs logs a message at the Warning level with params from key-value pairs.
+func (l *Logger) WarningAttrs(message string, args ...interface{}) {
+	l.log(LogLevelWarning, message, SetAttrs(args...))
+}
+
+// ErrorAttrs logs a message at the Error level with params from key-value pairs.
+func (l *Logger) ErrorAttrs(message string, args ...interface{}) {
+	l.log(LogLevelError, message, SetAttrs(args...))
+}
+
+// FatalAttrs logs a message at the Fatal level with params from key-value pairs,
+// and exits the process like Fatal.
+func (l *Logger) FatalAttrs(message string, args ...interface{}) {
+	l.log(LogLevelFatal, message, SetAttrs(args...))
+	l.exitFatal()
+}
@@ -0,0 +1,82 @@
+package asynclog
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []interface{}
+		want map[string]interface{}
+	}{
+		{"pairs", []interface{}{"user_id", 123, "action", "login"},
+			map[string]interface{}{"user_id": 123, "action": "login"}},
+		{"key without a value", []interface{}{"user_id", 123, "orphan"},
+			map[string]interface{}{"user_id": 123, BadKey: "orphan"}},
+		{"value without a key", []interface{}{42, "action", "login"},
+			map[string]interface{}{BadKey: 42, "action": "login"}},
+		{"no args", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m LogMessage
+			SetAttrs(tt.args...)(&m)
+			if !reflect.DeepEqual(m.Params, tt.want) {
+				t.Errorf("params = %v, want %v", m.Params, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetAttrsReusedOption(t *testing.T) {
+	var mu sync.Mutex
+	var got []map[string]interface{}
+	l, _ := newTestLogger(t, SetMode(Synchronous))
+	l.AddHook(func(m LogMessage) {
+		mu.Lock()
+		got = append(got, m.Params)
+		mu.Unlock()
+	})
+
+	// The same option value is used twice in a row and then concurrently
+	opt := SetAttrs("user_id", 123, "action", "login")
+	l.Info("first", opt)
+	l.Info("second", opt)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.Info("concurrent", opt)
+		}()
+	}
+	wg.Wait()
+
+	want := map[string]interface{}{"user_id": 123, "action": "login"}
+	if len(got) != 6 {
+		t.Fatalf("hook received %d messages, want 6", len(got))
+	}
+	for i, params := range got {
+		if !reflect.DeepEqual(params, want) {
+			t.Errorf("message %d params = %v, want %v", i, params, want)
+		}
+	}
+}
+
+func TestAttrsMethods(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous))
+
+	l.InfoAttrs("user action", "user_id", 123)
+	l.ErrorAttrs("failed", "code", 7)
+
+	got := readFile(t, file)
+	for _, want := range []string{"INFO: user action", `"user_id": 123`, "ERROR: failed", `"code": 7`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("file = %q, want it to contain %q", got, want)
+		}
+	}
+}
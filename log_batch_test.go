@@ -0,0 +1,91 @@
+package asynclog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingWriter records every write call it receives.
+type countingWriter struct {
+	mu     sync.Mutex
+	writes []string
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestSetCoalesceWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     time.Duration
+		wantWrites int
+	}{
+		{"disabled", 0, 3},
+		{"coalesced", 50 * time.Millisecond, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &countingWriter{}
+			l, _ := newTestLogger(t, SetFileWriter(w), SetCoalesceWindow(tt.window))
+
+			for _, message := range []string{"one", "two", "three"} {
+				l.Info(message)
+			}
+			if !l.WaitIdle(time.Second) {
+				t.Fatal("WaitIdle timed out")
+			}
+
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if len(w.writes) != tt.wantWrites {
+				t.Errorf("%d write calls, want %d", len(w.writes), tt.wantWrites)
+			}
+			got := strings.Join(w.writes, "")
+			if strings.Index(got, "one") > strings.Index(got, "two") || strings.Index(got, "two") > strings.Index(got, "three") {
+				t.Errorf("records = %q, want them in logging order", got)
+			}
+		})
+	}
+}
+
+func TestSetCoalesceWindowRejectsNegative(t *testing.T) {
+	if _, err := NewLogger(SetCoalesceWindow(-time.Second)); err == nil {
+		t.Error("NewLogger accepted a negative window")
+	}
+}
+
+func TestCoalescedBatchStopsWhenDiscarding(t *testing.T) {
+	l, file := newTestLogger(t, SetCoalesceWindow(50*time.Millisecond))
+	release := make(chan struct{})
+	var processed sync.WaitGroup
+	processed.Add(1)
+	l.AddHook(func(m LogMessage) {
+		if m.Message == "first" {
+			processed.Done()
+			<-release
+		}
+	})
+
+	// All messages arrive within the window, so they form a single batch
+	l.Info("first")
+	l.Info("skipped")
+	l.Info("also skipped")
+	processed.Wait()
+
+	done := make(chan error, 1)
+	go func() { done <- l.Shutdown(10*time.Millisecond, nil) }()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	<-done
+
+	got := readFile(t, file)
+	if !strings.Contains(got, "INFO: first") || strings.Contains(got, "skipped") {
+		t.Errorf("file = %q, want only the message in progress", got)
+	}
+}
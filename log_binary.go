@@ -0,0 +1,289 @@
+package asynclog
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+// FileFormat selects the encoding of file output.
+type FileFormat int
+
+const (
+	// FormatText writes human-readable, newline-terminated lines.
+	FormatText FileFormat = iota
+
+	// FormatBinary writes compact length-prefixed binary records, trading
+	// readability for throughput and size. Use ConvertBinaryLog or the
+	// asynclog-convert command to render binary logs as text.
+	FormatBinary
+)
+
+// Binary value tags identifying the encoding of a param value.
+const (
+	binaryNil byte = iota
+	binaryString
+	binaryInt
+	binaryFloat
+	binaryBool
+	binaryJSON
+)
+
+// SetFileFormat selects the encoding of file output. In FormatBinary, line
+// formatters, format version headers and line HMACs do not apply.
+func SetFileFormat(format FileFormat) LoggerOption {
+	return func(l *Logger) error {
+		if format != FormatText && format != FormatBinary {
+			return fmt.Errorf("unknown file format: %d", format)
+		}
+		l.fileFormat = format
+		return nil
+	}
+}
+
+// EncodeBinaryRecord encodes a message as a binary record: a 4-byte big-endian
+// length followed by the level byte, the varint Unix timestamp in nanoseconds,
+// the message, component, event and source strings, and the params sorted by key.
+// Strings are prefixed with their uvarint length.
+func EncodeBinaryRecord(m LogMessage) []byte {
+	buf := make([]byte, 4, 64+len(m.Message))
+	buf = append(buf, byte(m.Level))
+	buf = binary.AppendVarint(buf, m.Time.UnixNano())
+	buf = appendBinaryString(buf, m.Message)
+	buf = appendBinaryString(buf, m.Component)
+	buf = appendBinaryString(buf, m.Event)
+	buf = appendBinaryString(buf, m.Source)
+
+	keys := make([]string, 0, len(m.Params))
+	for key := range m.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	buf = binary.AppendUvarint(buf, uint64(len(keys)))
+	for _, key := range keys {
+		buf = appendBinaryString(buf, key)
+		buf = appendBinaryValue(buf, m.Params[key])
+	}
+
+	binary.BigEndian.PutUint32(buf, uint32(len(buf)-4))
+	return buf
+}
+
+// appendBinaryString appends a uvarint length-prefixed string.
+func appendBinaryString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// appendBinaryValue appends a tagged param value. Types without a compact
+// encoding are stored as JSON.
+func appendBinaryValue(buf []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, binaryNil)
+	case string:
+		return appendBinaryString(append(buf, binaryString), v)
+	case int:
+		return binary.AppendVarint(append(buf, binaryInt), int64(v))
+	case int32:
+		return binary.AppendVarint(append(buf, binaryInt), int64(v))
+	case int64:
+		return binary.AppendVarint(append(buf, binaryInt), v)
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, binaryFloat), math.Float64bits(v))
+	case bool:
+		if v {
+			return append(buf, binaryBool, 1)
+		}
+		return append(buf, binaryBool, 0)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			data, _ = json.Marshal(fmt.Sprintf("%v", v))
+		}
+		return appendBinaryString(append(buf, binaryJSON), string(data))
+	}
+}
+
+// BinaryReader decodes binary records written in FormatBinary.
+type BinaryReader struct {
+	r *bufio.Reader // Buffered source of records.
+}
+
+// NewBinaryReader returns a reader decoding binary records from r.
+func NewBinaryReader(r io.Reader) *BinaryReader {
+	return &BinaryReader{r: bufio.NewReader(r)}
+}
+
+// Next decodes the next record. It returns io.EOF after the last record.
+func (br *BinaryReader) Next() (LogMessage, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(br.r, header[:]); err != nil {
+		return LogMessage{}, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return LogMessage{}, fmt.Errorf("binary log record too large: %d bytes", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return LogMessage{}, err
+	}
+	return DecodeBinaryRecord(data)
+}
+
+// errBinaryTruncated is returned for records ending in the middle of a field.
+var errBinaryTruncated = errors.New("truncated binary log record")
+
+// binaryDecoder reads the fields of a single record payload.
+type binaryDecoder struct {
+	data []byte // Remaining undecoded payload.
+	err  error  // First decoding error.
+}
+
+// DecodeBinaryRecord decodes a record payload, without its length prefix.
+func DecodeBinaryRecord(data []byte) (LogMessage, error) {
+	d := &binaryDecoder{data: data}
+	var m LogMessage
+
+	m.Level = LogLevel(d.byte())
+	m.Time = time.Unix(0, d.varint())
+	m.Message = d.string()
+	m.Component = d.string()
+	m.Event = d.string()
+	m.Source = d.string()
+
+	count := d.uvarint()
+	if d.err == nil && count > uint64(len(d.data)) {
+		d.err = errBinaryTruncated
+	}
+	if d.err == nil && count > 0 {
+		m.Params = make(map[string]interface{}, count)
+		for i := uint64(0); i < count && d.err == nil; i++ {
+			key := d.string()
+			m.Params[key] = d.value()
+		}
+	}
+	return m, d.err
+}
+
+// byte decodes a single byte.
+func (d *binaryDecoder) byte() byte {
+	if d.err != nil || len(d.data) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+// varint decodes a signed varint.
+func (d *binaryDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// uvarint decodes an unsigned varint.
+func (d *binaryDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// string decodes a length-prefixed string.
+func (d *binaryDecoder) string() string {
+	size := d.uvarint()
+	if d.err != nil || size > uint64(len(d.data)) {
+		d.fail()
+		return ""
+	}
+	s := string(d.data[:size])
+	d.data = d.data[size:]
+	return s
+}
+
+// value decodes a tagged param value.
+func (d *binaryDecoder) value() interface{} {
+	switch tag := d.byte(); tag {
+	case binaryNil:
+		return nil
+	case binaryString:
+		return d.string()
+	case binaryInt:
+		return d.varint()
+	case binaryFloat:
+		if d.err != nil || len(d.data) < 8 {
+			d.fail()
+			return nil
+		}
+		v := math.Float64frombits(binary.BigEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return v
+	case binaryBool:
+		return d.byte() != 0
+	case binaryJSON:
+		var v interface{}
+		if err := json.Unmarshal([]byte(d.string()), &v); err != nil && d.err == nil {
+			d.err = err
+		}
+		return v
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown binary value tag: %d", tag)
+		}
+		return nil
+	}
+}
+
+// fail records a truncation error unless an error was already recorded.
+func (d *binaryDecoder) fail() {
+	if d.err == nil {
+		d.err = errBinaryTruncated
+	}
+}
+
+// ConvertBinaryLog renders every binary record read from r as a line written to w,
+// using the formatter, or FormatLineAsText if it is nil.
+func ConvertBinaryLog(r io.Reader, w io.Writer, formatter LineFormatter) error {
+	if formatter == nil {
+		formatter = FormatLineAsText
+	}
+	reader := NewBinaryReader(r)
+	for {
+		m, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, formatter(m)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package asynclog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBinaryRecordRoundTrip(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 123, time.UTC)
+	tests := []struct {
+		name string
+		msg  LogMessage
+	}{
+		{"message only", LogMessage{Level: LogLevelInfo, Time: at, Message: "started"}},
+		{"all strings", LogMessage{Level: LogLevelError, Time: at, Message: "failed", Component: "db", Event: "db.error", Source: "main.go:42"}},
+		{"params", LogMessage{Level: LogLevelWarning, Time: at, Message: "slow", Params: map[string]interface{}{
+			"name": "query", "rows": int64(3), "ratio": 0.5, "cached": true, "missing": nil,
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := EncodeBinaryRecord(tt.msg)
+			got, err := DecodeBinaryRecord(record[4:])
+			if err != nil {
+				t.Fatalf("DecodeBinaryRecord: %v", err)
+			}
+			if FormatLineAsText(got) != FormatLineAsText(tt.msg) {
+				t.Errorf("decoded %q, want %q", FormatLineAsText(got), FormatLineAsText(tt.msg))
+			}
+			if !got.Time.Equal(tt.msg.Time) {
+				t.Errorf("decoded time %v, want %v", got.Time, tt.msg.Time)
+			}
+		})
+	}
+}
+
+func TestBinaryFileFormat(t *testing.T) {
+	var console bytes.Buffer
+	l, file := newTestLogger(t, SetMode(Synchronous), SetFileFormat(FormatBinary),
+		EnableConsoleOutput(true), SetConsoleWriter(&console), SetConsoleUsesFileFormat(true))
+
+	l.Info("started", SetLogParams(map[string]interface{}{"port": 8080}))
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	var text strings.Builder
+	if err := ConvertBinaryLog(bytes.NewReader(data), &text, nil); err != nil {
+		t.Fatalf("ConvertBinaryLog: %v", err)
+	}
+	for _, want := range []string{"INFO: started", `"port": 8080`} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("converted file = %q, want it to contain %q", text.String(), want)
+		}
+	}
+
+	// The console falls back to text instead of printing binary records
+	if got := console.String(); !strings.Contains(got, "INFO: started") || strings.ContainsRune(got, 0) {
+		t.Errorf("console = %q, want a text line", got)
+	}
+}
+
+func BenchmarkFileFormat(b *testing.B) {
+	formats := []struct {
+		name   string
+		format FileFormat
+	}{
+		{"text", FormatText},
+		{"binary", FormatBinary},
+	}
+	params := map[string]interface{}{"method": "GET", "path": "/search", "status": 200, "took": 1.5}
+	for _, f := range formats {
+		b.Run(f.name, func(b *testing.B) {
+			l, _ := newTestLogger(b, SetMode(Synchronous), SetFileFormat(f.format))
+			opt := SetLogParams(params)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("request handled", opt)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package asynclog
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultBufferFlushInterval is the default interval at which write buffers are flushed.
+const DefaultBufferFlushInterval = time.Second
+
+// SetWriteBuffer buffers the writes to each log file in memory, up to size
+// bytes, to save a system call per record under high volume. Buffers are
+// written out when they fill up, at every buffer flush interval, whenever a
+// file is flushed, e.g. because of SetFlushLevel, and on Close.
+// Buffered records are lost if the process crashes or exits without Close,
+// so a larger buffer trades durability for throughput.
+func SetWriteBuffer(size int) LoggerOption {
+	return func(l *Logger) error {
+		if size <= 0 {
+			return fmt.Errorf("write buffer size must be positive")
+		}
+		l.writeBuffer = size
+		return nil
+	}
+}
+
+// SetBufferFlushInterval sets the interval at which write buffers are written
+// out, which bounds how old the records lost in a crash can be.
+// It defaults to DefaultBufferFlushInterval.
+func SetBufferFlushInterval(interval time.Duration) LoggerOption {
+	return func(l *Logger) error {
+		if interval <= 0 {
+			return fmt.Errorf("buffer flush interval must be positive")
+		}
+		l.bufferInterval = interval
+		return nil
+	}
+}
+
+// writeData writes data to an open file, through its write buffer if enabled.
+// The caller must hold fileMutex.
+func (l *Logger) writeData(filename string, file *os.File, data string) error {
+	if l.writeBuffer <= 0 {
+		_, err := file.WriteString(data)
+		return err
+	}
+	buffer := l.buffers[filename]
+	if buffer == nil {
+		buffer = bufio.NewWriterSize(file, l.writeBuffer)
+		l.buffers[filename] = buffer
+	}
+	_, err := buffer.WriteString(data)
+	return err
+}
+
+// flushBufferLocked writes out the write buffer of a file.
+// The caller must hold fileMutex.
+func (l *Logger) flushBufferLocked(filename string) error {
+	buffer := l.buffers[filename]
+	if buffer == nil || buffer.Buffered() == 0 {
+		return nil
+	}
+	if err := buffer.Flush(); err != nil {
+		l.recordWriteError(err)
+		l.diagf("Failed to flush write buffer: %w", err)
+		return err
+	}
+	return nil
+}
+
+// flushBuffers writes out the write buffers of all open files.
+func (l *Logger) flushBuffers() {
+	l.fileMutex.Lock()
+	defer l.fileMutex.Unlock()
+
+	for filename := range l.buffers {
+		l.flushBufferLocked(filename)
+	}
+}
+
+// bufferFlushLoop periodically writes out the write buffers until the logger is closed.
+func (l *Logger) bufferFlushLoop() {
+	interval := l.bufferInterval
+	if interval <= 0 {
+		interval = DefaultBufferFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.flushBuffers()
+		case <-l.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteBuffer(t *testing.T) {
+	tests := []struct {
+		name  string
+		flush func(*Logger)
+	}{
+		{"close", func(l *Logger) { l.Close() }},
+		{"flush level", func(l *Logger) { l.Error("failed") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetWriteBuffer(4096), SetFlushLevel(LogLevelError))
+			l.Info("buffered")
+			if got := readFile(t, file); got != "" {
+				t.Fatalf("file = %q, want records held in the buffer", got)
+			}
+
+			tt.flush(l)
+			if got := readFile(t, file); !strings.Contains(got, "INFO: buffered\n") {
+				t.Errorf("file = %q, want the buffered record written", got)
+			}
+		})
+	}
+}
+
+func BenchmarkWriteBuffer(b *testing.B) {
+	tests := []struct {
+		name string
+		size int
+	}{
+		{"unbuffered", 0},
+		{"buffered", 64 * 1024},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			opts := []LoggerOption{SetMode(Synchronous)}
+			if tt.size > 0 {
+				opts = append(opts, SetWriteBuffer(tt.size))
+			}
+			l, _ := newTestLogger(b, opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("request handled")
+			}
+		})
+	}
+}
+
+func TestSetFlushEveryN(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetWriteBuffer(4096),
+		SetBufferFlushInterval(time.Hour), SetFlushEveryN(3))
+
+	// Buffered records reach the file with every third message
+	steps := []struct {
+		message   string
+		wantLines int
+	}{
+		{"one", 0},
+		{"two", 0},
+		{"three", 3},
+		{"four", 3},
+	}
+	for _, step := range steps {
+		l.Info(step.message)
+		if got := strings.Count(readFile(t, file), "\n"); got != step.wantLines {
+			t.Fatalf("after %q the file has %d lines, want %d", step.message, got, step.wantLines)
+		}
+	}
+
+	// Close flushes the remainder
+	l.Close()
+	if got := strings.Count(readFile(t, file), "\n"); got != 4 {
+		t.Errorf("after Close the file has %d lines, want 4", got)
+	}
+}
+
+func TestSetFlushEveryNRejectsNonPositive(t *testing.T) {
+	if _, err := NewLogger(SetFlushEveryN(0)); err == nil {
+		t.Error("NewLogger accepted a count of 0")
+	}
+}
+
+func TestFlushFile(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetWriteBuffer(4096),
+		SetBufferFlushInterval(time.Hour), SetFileNameSanitizer(acceptFileName))
+	job := filepath.Join(filepath.Dir(file), "job-123.log")
+
+	l.Info("started")
+	l.Info("job done", SetLogFile(job))
+
+	if err := l.FlushFile(job); err != nil {
+		t.Fatalf("FlushFile(job): %v", err)
+	}
+	if got := readFile(t, job); !strings.Contains(got, "INFO: job done\n") {
+		t.Errorf("job file = %q, want the flushed record", got)
+	}
+	if got := readFile(t, file); got != "" {
+		t.Errorf("default file = %q, want its records still buffered", got)
+	}
+
+	// An empty name refers to the default file
+	if err := l.FlushFile(""); err != nil {
+		t.Fatalf("FlushFile(\"\"): %v", err)
+	}
+	if got := readFile(t, file); !strings.Contains(got, "INFO: started\n") {
+		t.Errorf("default file = %q, want the flushed record", got)
+	}
+
+	if err := l.FlushFile(filepath.Join(filepath.Dir(file), "never.log")); err == nil {
+		t.Error("FlushFile succeeded for a file that is not open")
+	}
+}
@@ -0,0 +1,107 @@
+package asynclog
+
+import "errors"
+
+// OnLevel registers a callback invoked for every processed message at the given level,
+// e.g. to page someone on Fatal or to count Errors. Several callbacks may be
+// registered per level. Callbacks run in their own goroutine, off the write path,
+// so a slow callback does not delay logging; a panicking callback is recovered.
+func (l *Logger) OnLevel(level LogLevel, callback func(LogMessage)) {
+	root := l.root()
+	root.callbackMutex.Lock()
+	defer root.callbackMutex.Unlock()
+
+	if root.levelCallbacks == nil {
+		root.levelCallbacks = make(map[LogLevel][]func(LogMessage))
+	}
+	root.levelCallbacks[level] = append(root.levelCallbacks[level], callback)
+}
+
+// runLevelCallbacks starts the callbacks registered for the message level.
+func (l *Logger) runLevelCallbacks(logMessage LogMessage) {
+	l.callbackMutex.RLock()
+	callbacks := l.levelCallbacks[logMessage.Level]
+	l.callbackMutex.RUnlock()
+
+	l.callbacks.Add(len(callbacks))
+	for _, callback := range callbacks {
+		go l.runCallback(callback, logMessage)
+	}
+}
+
+// runCallback invokes a callback, recovering from any panic it raises.
+func (l *Logger) runCallback(callback func(LogMessage), logMessage LogMessage) {
+	defer l.callbacks.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			l.diagf("Log callback panicked: %v", r)
+		}
+	}()
+	callback(logMessage)
+}
+
+// AddHook registers a hook invoked for every processed message, e.g. to count
+// messages per level for metrics. Unlike OnLevel callbacks, hooks run
+// synchronously on the log processing goroutine (or the caller's goroutine in
+// synchronous mode), in registration order, before the message is written,
+// so they must return quickly. A panicking hook is recovered and does not
+// keep the other hooks or the message from being processed.
+// Hooks must not log through the same logger, nor call Close, Shutdown or
+// Fatal on it: they run while the message is processed, so doing so deadlocks.
+// Use an OnLevel callback, which runs in its own goroutine, for that instead.
+func (l *Logger) AddHook(hook func(LogMessage)) {
+	root := l.root()
+	root.callbackMutex.Lock()
+	defer root.callbackMutex.Unlock()
+
+	root.hooks = append(root.hooks, hook)
+}
+
+// runHooks invokes the hooks in registration order.
+func (l *Logger) runHooks(logMessage LogMessage) {
+	l.callbackMutex.RLock()
+	hooks := l.hooks
+	l.callbackMutex.RUnlock()
+
+	for _, hook := range hooks {
+		l.runHook(hook, logMessage)
+	}
+}
+
+// runHook invokes a hook, recovering from any panic it raises.
+func (l *Logger) runHook(hook func(LogMessage), logMessage LogMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			l.diagf("Log hook panicked: %v", r)
+		}
+	}()
+	hook(logMessage)
+}
+
+// OnClose registers a callback run when the logger is closed by Close or Shutdown,
+// after queued messages have been written, e.g. to tear down a custom sink.
+// Callbacks run once, in reverse order of registration, and their errors are
+// joined into the error returned by Close.
+func (l *Logger) OnClose(callback func() error) {
+	root := l.root()
+	root.callbackMutex.Lock()
+	defer root.callbackMutex.Unlock()
+
+	root.closeFuncs = append(root.closeFuncs, callback)
+}
+
+// runCloseCallbacks runs the OnClose callbacks in reverse order and joins their errors.
+func (l *Logger) runCloseCallbacks() error {
+	l.callbackMutex.Lock()
+	callbacks := l.closeFuncs
+	l.closeFuncs = nil
+	l.callbackMutex.Unlock()
+
+	var errs []error
+	for i := len(callbacks) - 1; i >= 0; i-- {
+		if err := callbacks[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package asynclog
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddHook(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags []string
+			l, file := newTestLogger(t, SetMode(tt.mode), SetFileLevel(LogLevelTrace),
+				SetErrorHandler(func(err error) { diags = append(diags, err.Error()) }))
+
+			// Hooks run on a single goroutine, so the calls need no locking
+			var calls []string
+			l.AddHook(func(m LogMessage) { calls = append(calls, "first:"+m.Message) })
+			l.AddHook(func(m LogMessage) {
+				if m.Message == "two" {
+					panic("hook failed")
+				}
+				calls = append(calls, "second:"+m.Message)
+			})
+			l.AddHook(func(m LogMessage) { calls = append(calls, "third:"+m.Message) })
+
+			l.Debug("one")
+			l.Info("two")
+			l.Close()
+
+			want := []string{"first:one", "second:one", "third:one", "first:two", "third:two"}
+			if strings.Join(calls, " ") != strings.Join(want, " ") {
+				t.Errorf("hook calls = %v, want %v", calls, want)
+			}
+			// A panicking hook keeps neither the message nor the other hooks from running
+			if got := readFile(t, file); !strings.Contains(got, "INFO: two\n") {
+				t.Errorf("file = %q, want the message of the panicking hook", got)
+			}
+			if len(diags) != 1 || !strings.Contains(diags[0], "Log hook panicked: hook failed") {
+				t.Errorf("diagnostics = %v, want one reporting the panic", diags)
+			}
+		})
+	}
+}
+
+func TestAddHookCountsMessages(t *testing.T) {
+	l, _ := newTestLogger(t, SetFileLevel(LogLevelTrace))
+	var mu sync.Mutex
+	counts := make(map[LogLevel]int)
+	l.AddHook(func(m LogMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		counts[m.Level]++
+	})
+
+	// Hooks of a child logger are registered on its root
+	child := l.Named("worker")
+	for i := 0; i < 10; i++ {
+		l.Info("message")
+		child.Error("failed")
+	}
+	if !l.WaitIdle(time.Second) {
+		t.Fatal("logger did not become idle")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if counts[LogLevelInfo] != 10 || counts[LogLevelError] != 10 {
+		t.Errorf("hook counts = %v, want 10 Info and 10 Error", counts)
+	}
+}
+
+func TestOnClose(t *testing.T) {
+	l, _ := newTestLogger(t)
+	var order []int
+	errFirst := errors.New("first failed")
+	l.OnClose(func() error { order = append(order, 1); return errFirst })
+	l.OnClose(func() error { order = append(order, 2); return nil })
+	l.OnClose(func() error { order = append(order, 3); return nil })
+
+	if err := l.Close(); !errors.Is(err, errFirst) {
+		t.Errorf("Close error = %v, want %v", err, errFirst)
+	}
+	if !reflect.DeepEqual(order, []int{3, 2, 1}) {
+		t.Errorf("callbacks ran in order %v, want reverse registration order", order)
+	}
+
+	// Callbacks run only once
+	l.Close()
+	if len(order) != 3 {
+		t.Errorf("callbacks ran %d times, want 3", len(order))
+	}
+}
@@ -0,0 +1,103 @@
+package asynclog
+
+// Named returns a child logger whose messages are prefixed with the given
+// component name, e.g. "[auth] user logged in". Naming a child logger again
+// appends to the name with a dot, e.g. "auth.session".
+// The child shares the channel, file handles and settings of its parent.
+func (l *Logger) Named(name string) *Logger {
+	child := l.child()
+	if l.name != "" && name != "" {
+		child.name = l.name + "." + name
+	} else if name != "" {
+		child.name = name
+	}
+	return child
+}
+
+// WithGroup returns a child logger that nests the params of its messages
+// under the group name. Structured formatters such as JSON render nested
+// objects, e.g. {"http": {"method": "GET"}}, while flat formatters prefix
+// the keys, e.g. "http.method". Groups of nested child loggers stack.
+// An empty name returns the logger unchanged.
+func (l *Logger) WithGroup(name string) *Logger {
+	if name == "" {
+		return l
+	}
+	child := l.child()
+	child.groups = append(l.groups[:len(l.groups):len(l.groups)], name)
+	return child
+}
+
+// WithFields returns a child logger that adds the fields as params to every
+// message, e.g. logger.WithFields(map[string]interface{}{"service": "api"}).
+// Fields of nested child loggers are merged, and per-call params are combined
+// with them according to the ParamCollision policy, so by default they override
+// a bound field with the same key.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	child := l.child()
+	if len(fields) == 0 {
+		return child
+	}
+	child.fields = make(map[string]interface{}, len(l.fields)+len(fields))
+	for key, value := range l.fields {
+		child.fields[key] = value
+	}
+	for key, value := range fields {
+		child.fields[key] = value
+	}
+	return child
+}
+
+// child returns a new child logger carrying the context of l.
+func (l *Logger) child() *Logger {
+	return &Logger{
+		parent: l.root(),
+		name:   l.name,
+		groups: l.groups,
+		fields: l.fields,
+	}
+}
+
+// root returns the logger that owns the channel, file handles and settings.
+func (l *Logger) root() *Logger {
+	if l.parent != nil {
+		return l.parent
+	}
+	return l
+}
+
+// paramGroup holds the params nested under a group by WithGroup or a log/slog group.
+// Unlike maps logged as param values, groups are flattened into dotted keys by
+// the key-value and logfmt formatters.
+type paramGroup map[string]interface{}
+
+// groupParams nests params under the given groups, outermost group first.
+func groupParams(params map[string]interface{}, groups []string) map[string]interface{} {
+	if len(params) == 0 {
+		return params
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		params = map[string]interface{}{groups[i]: paramGroup(params)}
+	}
+	return params
+}
+
+// nestedParams returns the params of a nested param map, either a group or a
+// map logged as a param value.
+func nestedParams(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case paramGroup:
+		return v, true
+	case map[string]interface{}:
+		return v, true
+	}
+	return nil, false
+}
+
+// renest returns params as the same kind of nested param map as value.
+func renest(value interface{}, params map[string]interface{}) interface{} {
+	if _, ok := value.(paramGroup); ok {
+		return paramGroup(params)
+	}
+	return params
+}
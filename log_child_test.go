@@ -0,0 +1,113 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		groups    []string
+		params    map[string]interface{}
+		formatter ParamFormatter
+		want      []string
+	}{
+		{
+			name:      "key-value prefixes grouped keys",
+			groups:    []string{"http"},
+			params:    map[string]interface{}{"method": "GET"},
+			formatter: FormatParamsAsKeyValue,
+			want:      []string{`"http.method": GET`},
+		},
+		{
+			name:      "nested groups stack",
+			groups:    []string{"http", "request"},
+			params:    map[string]interface{}{"id": 7},
+			formatter: FormatParamsAsLogfmt,
+			want:      []string{"http.request.id=7"},
+		},
+		{
+			name:      "json nests grouped keys",
+			groups:    []string{"http"},
+			params:    map[string]interface{}{"status": 200},
+			formatter: FormatParamsAsJSON,
+			want:      []string{`"http": {`, `"status": 200`},
+		},
+		{
+			name:      "map values are not flattened",
+			params:    map[string]interface{}{"headers": map[string]interface{}{"accept": "json"}},
+			formatter: FormatParamsAsKeyValue,
+			want:      []string{`"headers": map[accept:json]`},
+		},
+		{
+			name:      "map values inside groups are not flattened",
+			groups:    []string{"http"},
+			params:    map[string]interface{}{"headers": map[string]interface{}{"accept": "json"}},
+			formatter: FormatParamsAsKeyValue,
+			want:      []string{`"http.headers": map[accept:json]`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetParamFormatter(tt.formatter))
+			child := l
+			for _, group := range tt.groups {
+				child = child.WithGroup(group)
+			}
+
+			child.Info("request", SetLogParams(tt.params))
+
+			got := readFile(t, file)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func(*Logger)
+		want   string
+		absent string
+	}{
+		{"bound field", func(l *Logger) {
+			l.WithFields(map[string]interface{}{"service": "api"}).Info("started")
+		}, "service=api", ""},
+		{"merged with call params", func(l *Logger) {
+			l.WithFields(map[string]interface{}{"service": "api"}).
+				Info("login", SetLogParams(map[string]interface{}{"user": "bob"}))
+		}, "service=api user=bob", ""},
+		{"call params override", func(l *Logger) {
+			l.WithFields(map[string]interface{}{"user": "alice"}).
+				Info("login", SetLogParams(map[string]interface{}{"user": "bob"}))
+		}, "user=bob", "alice"},
+		{"nested children merge", func(l *Logger) {
+			l.WithFields(map[string]interface{}{"service": "api", "region": "eu"}).
+				WithFields(map[string]interface{}{"region": "us"}).Info("started")
+		}, "region=us service=api", "eu"},
+		{"parent unchanged", func(l *Logger) {
+			l.WithFields(map[string]interface{}{"service": "api"})
+			l.Info("started")
+		}, "INFO: started\n", "service"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetParamFormatter(FormatParamsAsLogfmt))
+
+			tt.log(l)
+
+			got := readFile(t, file)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.absent != "" && strings.Contains(got, tt.absent) {
+				t.Errorf("file = %q, want it without %q", got, tt.absent)
+			}
+		})
+	}
+}
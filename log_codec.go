@@ -0,0 +1,59 @@
+package asynclog
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxFrameSize is the largest serialized message accepted by ReadMessage.
+const maxFrameSize = 16 << 20
+
+// MarshalBinary encodes the message, including its params, for transport
+// to another process. The encoding is JSON, so param values are decoded
+// as generic JSON values (numbers become float64, structs become maps).
+func (m LogMessage) MarshalBinary() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// UnmarshalBinary decodes a message encoded with MarshalBinary.
+func (m *LogMessage) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, m)
+}
+
+// WriteMessage writes a message to w as a length-prefixed frame,
+// to be read back with ReadMessage.
+func WriteMessage(w io.Writer, m LogMessage) error {
+	data, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(frameMessage(LengthPrefixed, string(data)))
+	return err
+}
+
+// ReadMessage reads a message written with WriteMessage from r.
+// It returns io.EOF when r ends cleanly before a new frame.
+func ReadMessage(r io.Reader) (LogMessage, error) {
+	var m LogMessage
+
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return m, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return m, fmt.Errorf("log message frame too large: %d bytes", size)
+	}
+
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return m, err
+	}
+	err := m.UnmarshalBinary(data)
+	return m, err
+}
@@ -0,0 +1,92 @@
+package asynclog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMessageCodecRoundTrip(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		name string
+		msg  LogMessage
+	}{
+		{"message only", LogMessage{Level: LogLevelInfo, Time: at, Message: "started"}},
+		{"all fields", LogMessage{Level: LogLevelError, Time: at, Message: "failed", File: "db.log",
+			Component: "db", Event: "db.error", Source: "main.go:42"}},
+		{"params", LogMessage{Level: LogLevelWarning, Time: at, Message: "slow", Params: map[string]interface{}{
+			"query": "SELECT 1", "rows": float64(3), "cached": true, "missing": nil,
+			"nested": map[string]interface{}{"host": "db1"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteMessage(&buf, tt.msg); err != nil {
+				t.Fatalf("WriteMessage: %v", err)
+			}
+			got, err := ReadMessage(&buf)
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			if !got.Time.Equal(tt.msg.Time) {
+				t.Errorf("time = %v, want %v", got.Time, tt.msg.Time)
+			}
+			got.Time = tt.msg.Time
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("ReadMessage = %+v, want %+v", got, tt.msg)
+			}
+			if _, err := ReadMessage(&buf); err != io.EOF {
+				t.Errorf("ReadMessage at the end error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestReadMessageInvalidInput(t *testing.T) {
+	var valid bytes.Buffer
+	if err := WriteMessage(&valid, LogMessage{Level: LogLevelInfo, Message: "started"}); err != nil {
+		t.Fatalf("WriteMessage: %v", err)
+	}
+	frame := valid.Bytes()
+	oversized := make([]byte, 4)
+	binary.BigEndian.PutUint32(oversized, maxFrameSize+1)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		wantErr error
+	}{
+		{"truncated header", frame[:2], io.ErrUnexpectedEOF},
+		{"truncated body", frame[:len(frame)-3], io.ErrUnexpectedEOF},
+		{"corrupt body", append(append([]byte(nil), frame[:4]...), bytes.Repeat([]byte{'}'}, len(frame)-4)...), nil},
+		{"oversized frame", oversized, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadMessage(bytes.NewReader(tt.input))
+			if err == nil || err == io.EOF {
+				t.Fatalf("ReadMessage error = %v, want a decoding error", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadMessage error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteMessageUnencodableParam(t *testing.T) {
+	var buf bytes.Buffer
+	m := LogMessage{Message: "bad", Params: map[string]interface{}{"ch": make(chan int)}}
+	if err := WriteMessage(&buf, m); err == nil {
+		t.Error("WriteMessage accepted a channel param")
+	}
+	if buf.Len() > 0 {
+		t.Errorf("WriteMessage wrote %d bytes for a failed message", buf.Len())
+	}
+}
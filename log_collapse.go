@@ -0,0 +1,84 @@
+package asynclog
+
+import "fmt"
+
+// lastLine is the message written last to a file, for collapsing repeats.
+type lastLine struct {
+	key     string   // Content of the message, without its time.
+	level   LogLevel // Level of the message.
+	repeats int      // Identical messages skipped since it was written.
+}
+
+// SetCollapseConsecutive collapses immediately consecutive identical messages
+// to the same file into the first one, followed by a "(repeated N times)" note
+// when a different message arrives or the logger is closed. Messages are
+// identical when everything but their time matches. Only the last message
+// per file is remembered, so collapsing uses no memory beyond that.
+func SetCollapseConsecutive(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.collapse = enable
+		return nil
+	}
+}
+
+// collapseRepeat reports whether the message repeats the last one of its file
+// and is skipped. Otherwise it writes the note for the repeats of the last one.
+func (l *Logger) collapseRepeat(logMessage LogMessage) bool {
+	key := fmt.Sprint(logMessage.Level, logMessage.Component, logMessage.Event,
+		logMessage.Source, logMessage.Message, logMessage.Params)
+
+	l.collapseMutex.Lock()
+	last := l.lastLines[logMessage.File]
+	if last != nil && last.key == key {
+		last.repeats++
+		l.collapseMutex.Unlock()
+		return true
+	}
+	var note string
+	if last != nil && last.repeats > 0 {
+		note = l.repeatNote(last)
+	}
+	if l.lastLines == nil {
+		l.lastLines = make(map[string]*lastLine)
+	}
+	l.lastLines[logMessage.File] = &lastLine{key: key, level: logMessage.Level}
+	l.collapseMutex.Unlock()
+
+	if note != "" {
+		l.queueFileWrite(logMessage.File, note, false)
+	}
+	return false
+}
+
+// flushCollapsed writes the notes for repeats not reported yet, e.g. on Close.
+func (l *Logger) flushCollapsed() {
+	notes := make(map[string]string)
+	l.settingsMutex.RLock()
+	l.collapseMutex.Lock()
+	for filename, last := range l.lastLines {
+		if last.repeats > 0 {
+			notes[filename] = l.repeatNote(last)
+			last.repeats = 0
+		}
+	}
+	l.collapseMutex.Unlock()
+	l.settingsMutex.RUnlock()
+
+	for filename, note := range notes {
+		l.writeFile(filename, note)
+	}
+}
+
+// repeatNote returns the file record noting how often the last message was repeated.
+func (l *Logger) repeatNote(last *lastLine) string {
+	logMsg := LogMessage{
+		Level:   last.level,
+		Message: fmt.Sprintf("(repeated %d times)", last.repeats),
+		Time:    l.now().In(l.timeLocation()),
+	}
+	record := l.prepareFileMessage(logMsg, l.formatTime(logMsg.Time), "", "")
+	if l.hmacKey != nil && l.fileFormat == FormatText {
+		record = l.signRecord(record)
+	}
+	return record
+}
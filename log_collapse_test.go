@@ -0,0 +1,42 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetCollapseConsecutive(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	stamp := "[2024/06/01 12:00:00] "
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(func() time.Time { return at }),
+		SetCollapseConsecutive(true), SetFileNameSanitizer(acceptFileName))
+	other := filepath.Join(filepath.Dir(file), "other.log")
+
+	l.Error("disk full")
+	l.Error("disk full")
+	// Another file does not interrupt the repeats
+	l.Error("disk full", SetLogFile(other))
+	l.Error("disk full")
+	l.Error("disk full", SetLogParams(map[string]interface{}{"disk": "sda"}))
+	l.Warning("retrying")
+	l.Warning("retrying")
+	l.Info("single")
+	l.Info("single")
+	l.Close()
+
+	want := stamp + "ERROR: disk full\n" +
+		stamp + "ERROR: (repeated 2 times)\n" +
+		stamp + "ERROR: disk full\n  \"disk\": sda\n" +
+		stamp + "WARNING: retrying\n" +
+		stamp + "WARNING: (repeated 1 times)\n" +
+		stamp + "INFO: single\n" +
+		// Repeats not reported yet are noted on close
+		stamp + "INFO: (repeated 1 times)\n"
+	if got := readFile(t, file); got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+	if got, want := readFile(t, other), stamp+"ERROR: disk full\n"; got != want {
+		t.Errorf("other file = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,123 @@
+package asynclog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// colorNames maps the accepted color and modifier names to color attributes.
+var colorNames = map[string]color.Attribute{
+	"black":     color.FgBlack,
+	"red":       color.FgRed,
+	"green":     color.FgGreen,
+	"yellow":    color.FgYellow,
+	"blue":      color.FgBlue,
+	"magenta":   color.FgMagenta,
+	"cyan":      color.FgCyan,
+	"white":     color.FgWhite,
+	"hiblack":   color.FgHiBlack,
+	"hired":     color.FgHiRed,
+	"higreen":   color.FgHiGreen,
+	"hiyellow":  color.FgHiYellow,
+	"hiblue":    color.FgHiBlue,
+	"himagenta": color.FgHiMagenta,
+	"hicyan":    color.FgHiCyan,
+	"hiwhite":   color.FgHiWhite,
+	"bold":      color.Bold,
+	"faint":     color.Faint,
+	"italic":    color.Italic,
+	"underline": color.Underline,
+	"blink":     color.BlinkSlow,
+	"reverse":   color.ReverseVideo,
+}
+
+// SetLevelColor sets the console color attributes used for a level,
+// replacing the built-in color of that level.
+func SetLevelColor(level LogLevel, attrs ...color.Attribute) LoggerOption {
+	return func(l *Logger) error {
+		if len(attrs) == 0 {
+			return fmt.Errorf("level color needs at least one attribute")
+		}
+		if l.levelColors == nil {
+			l.levelColors = make(map[LogLevel][]color.Attribute)
+		}
+		l.levelColors[level] = attrs
+		return nil
+	}
+}
+
+// ParseColor parses a color specification such as "red", "hiyellow+bold" or
+// "cyan+underline" into color attributes. A specification is a "+"-separated
+// list of names; case, dashes and underscores are ignored, so "Hi-Red" is valid.
+//
+// Colors: black, red, green, yellow, blue, magenta, cyan, white, and their
+// bright variants prefixed with "hi", e.g. hired.
+// Modifiers: bold, faint, italic, underline, blink, reverse.
+func ParseColor(spec string) ([]color.Attribute, error) {
+	var attrs []color.Attribute
+	for _, part := range strings.Split(spec, "+") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		name = strings.NewReplacer("-", "", "_", "").Replace(name)
+		attr, ok := colorNames[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q in %q", strings.TrimSpace(part), spec)
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// colorize formats text in the console color of the level, with optional bold styling.
+func (l *Logger) colorize(text string, level LogLevel, bold bool) string {
+	attrs, ok := l.levelColors[level]
+	if !ok {
+		return formatLogLevel(text, level, bold)
+	}
+	formatter := color.New(attrs...)
+	formatter.EnableColor()
+	if bold {
+		formatter = formatter.Add(color.Bold)
+	}
+	return formatter.SprintfFunc()(text)
+}
+
+// SetColorEnabled turns colored console output on or off. By default colors
+// are only used when the console stream is a terminal and neither the NO_COLOR
+// environment variable is set nor TERM is "dumb". With SetErrorToStderr, this
+// is decided separately for stdout and stderr, so "2>file" keeps escape
+// sequences out of the file while stdout stays colored.
+func SetColorEnabled(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.colorOverride = &enable
+		return nil
+	}
+}
+
+// isTerminal reports whether a file descriptor refers to a terminal.
+var isTerminal = term.IsTerminal
+
+// detectColors reports whether console output written to w should be colored.
+func (l *Logger) detectColors(w io.Writer) bool {
+	if l.colorOverride != nil {
+		return *l.colorOverride
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	console, ok := w.(*os.File)
+	return ok && isTerminal(int(console.Fd()))
+}
+
+// colorsFor reports whether console messages of the level are colored,
+// depending on the writer they are printed to.
+func (l *Logger) colorsFor(level LogLevel) bool {
+	if l.errorConsole != nil && level.AtLeast(LogLevelWarning) {
+		return l.errorColors
+	}
+	return l.colors
+}
@@ -0,0 +1,154 @@
+package asynclog
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestConsoleColors(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []LoggerOption
+		noColor bool
+		want    bool
+	}{
+		{"disabled", []LoggerOption{SetColorEnabled(false)}, false, false},
+		{"disabled with level colors", []LoggerOption{SetColorEnabled(false), SetLevelColor(LogLevelError, color.FgRed)}, false, false},
+		{"NO_COLOR set", nil, true, false},
+		{"not a terminal", nil, false, false},
+		{"forced on", []LoggerOption{SetColorEnabled(true)}, false, true},
+		{"forced on despite NO_COLOR", []LoggerOption{SetColorEnabled(true)}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.noColor {
+				t.Setenv("NO_COLOR", "1")
+			}
+			var console bytes.Buffer
+			opts := append([]LoggerOption{SetMode(Synchronous), SetConsoleLevel(LogLevelTrace),
+				EnableConsoleOutput(true), SetConsoleWriter(&console)}, tt.opts...)
+			l, _ := newTestLogger(t, opts...)
+
+			l.Debug("details", SetLogParams(map[string]interface{}{"key": "value"}))
+			l.Error("failed")
+
+			got := console.String()
+			if escaped := strings.Contains(got, "\x1b["); escaped != tt.want {
+				t.Errorf("console = %q, escape sequences = %v, want %v", got, escaped, tt.want)
+			}
+			if !strings.Contains(got, "failed") {
+				t.Errorf("console = %q, want the messages", got)
+			}
+		})
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []color.Attribute
+		wantErr bool
+	}{
+		{"red", []color.Attribute{color.FgRed}, false},
+		{"hiyellow+bold", []color.Attribute{color.FgHiYellow, color.Bold}, false},
+		{" Hi-Red + under_line ", []color.Attribute{color.FgHiRed, color.Underline}, false},
+		{"plaid", nil, true},
+		{"red+", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseColor(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor error = %v, want error %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseColor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorsPerStream(t *testing.T) {
+	tests := []struct {
+		name                   string
+		stdoutTTY, stderrTTY   bool
+		wantStdout, wantStderr bool
+	}{
+		{"both terminals", true, true, true, true},
+		{"stderr redirected", true, false, true, false},
+		{"stdout redirected", false, true, false, true},
+		{"both redirected", false, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdoutR, stdoutW, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("Pipe: %v", err)
+			}
+			stderrR, stderrW, err := os.Pipe()
+			if err != nil {
+				t.Fatalf("Pipe: %v", err)
+			}
+			stdout, stderr, terminal := os.Stdout, os.Stderr, isTerminal
+			os.Stdout, os.Stderr = stdoutW, stderrW
+			isTerminal = func(fd int) bool {
+				return (tt.stdoutTTY && fd == int(stdoutW.Fd())) || (tt.stderrTTY && fd == int(stderrW.Fd()))
+			}
+			defer func() { os.Stdout, os.Stderr, isTerminal = stdout, stderr, terminal }()
+
+			l, _ := newTestLogger(t, SetMode(Synchronous), EnableConsoleOutput(true), SetErrorToStderr(true))
+			l.Info("started")
+			l.Error("failed")
+			stdoutW.Close()
+			stderrW.Close()
+
+			for _, out := range []struct {
+				name string
+				r    *os.File
+				want bool
+			}{{"stdout", stdoutR, tt.wantStdout}, {"stderr", stderrR, tt.wantStderr}} {
+				var buf bytes.Buffer
+				buf.ReadFrom(out.r)
+				if escaped := strings.Contains(buf.String(), "\x1b["); escaped != out.want {
+					t.Errorf("%s = %q, escape sequences = %v, want %v", out.name, buf.String(), escaped, out.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetColorOnlyAtLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level LogLevel
+		want  map[string]bool // Whether the line of each message is colored.
+	}{
+		{"errors only", LogLevelError, map[string]bool{"details": false, "started": false, "failed": true}},
+		{"info and above", LogLevelInfo, map[string]bool{"details": false, "started": true, "failed": true}},
+		{"everything", LogLevelTrace, map[string]bool{"details": true, "started": true, "failed": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			l, _ := newTestLogger(t, SetMode(Synchronous), EnableConsoleOutput(true), SetConsoleWriter(&console),
+				SetColorEnabled(true), SetColorOnlyAtLevel(tt.level))
+
+			l.Debug("details")
+			l.Info("started")
+			l.Error("failed")
+
+			for _, line := range strings.Split(strings.TrimSuffix(console.String(), "\n"), "\n") {
+				for message, want := range tt.want {
+					if strings.Contains(line, message) && strings.Contains(line, "\x1b[") != want {
+						t.Errorf("line %q colored = %v, want %v", line, !want, want)
+					}
+				}
+			}
+		})
+	}
+}
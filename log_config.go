@@ -0,0 +1,313 @@
+package asynclog
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Config holds the logger settings in a plain struct form,
+// suitable for loading from JSON or YAML configuration files.
+// Zero values leave the corresponding logger default untouched.
+type Config struct {
+	BufferSize      int    `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"`             // Size of the log message channel.
+	FileLevel       string `json:"file_level,omitempty" yaml:"file_level,omitempty"`               // Minimum file level, e.g. "info".
+	ConsoleLevel    string `json:"console_level,omitempty" yaml:"console_level,omitempty"`         // Minimum console level, e.g. "debug".
+	DefaultFileName string `json:"default_file_name,omitempty" yaml:"default_file_name,omitempty"` // Default log file name.
+	FileOutput      *bool  `json:"file_output,omitempty" yaml:"file_output,omitempty"`             // Enable or disable file output.
+	ConsoleOutput   *bool  `json:"console_output,omitempty" yaml:"console_output,omitempty"`       // Enable or disable console output.
+	AddSource       *bool  `json:"add_source,omitempty" yaml:"add_source,omitempty"`               // Add source file info in logs.
+	ParamFormat     string `json:"param_format,omitempty" yaml:"param_format,omitempty"`           // "keyvalue", "json" or "logfmt".
+	MaxFileHandles  int    `json:"max_file_handles,omitempty" yaml:"max_file_handles,omitempty"`   // Maximum number of file handles.
+	Mode            string `json:"mode,omitempty" yaml:"mode,omitempty"`                           // "async" or "sync".
+	MaxFileSize     int64  `json:"max_file_size,omitempty" yaml:"max_file_size,omitempty"`         // Size in bytes at which files are rotated.
+	MaxBackups      int    `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`             // Rotated generations or days kept per file.
+	DailyRotation   *bool  `json:"daily_rotation,omitempty" yaml:"daily_rotation,omitempty"`       // Enable or disable daily rotation.
+
+	// LevelColors maps level names to console color specifications parsed
+	// with ParseColor, e.g. {"error": "red+bold", "debug": "hiblack"}.
+	LevelColors map[string]string `json:"level_colors,omitempty" yaml:"level_colors,omitempty"`
+
+	paramFormatter ParamFormatter // Formatter captured by Snapshot, restored when ParamFormat is empty.
+}
+
+// NewLoggerFromConfig creates a new Logger from a Config.
+// Additional options are applied after the ones derived from the config.
+func NewLoggerFromConfig(cfg Config, opts ...LoggerOption) (*Logger, error) {
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewLogger(append(cfgOpts, opts...)...)
+}
+
+// Options translates the config into the equivalent logger options.
+// It returns an error if any of the config values is invalid.
+func (c Config) Options() ([]LoggerOption, error) {
+	var opts []LoggerOption
+
+	if c.BufferSize != 0 {
+		opts = append(opts, SetBufferSize(c.BufferSize))
+	}
+	if c.FileLevel != "" {
+		level, err := ParseLogLevel(c.FileLevel)
+		if err != nil {
+			return nil, fmt.Errorf("file_level: %w", err)
+		}
+		opts = append(opts, SetFileLevel(level))
+	}
+	if c.ConsoleLevel != "" {
+		level, err := ParseLogLevel(c.ConsoleLevel)
+		if err != nil {
+			return nil, fmt.Errorf("console_level: %w", err)
+		}
+		opts = append(opts, SetConsoleLevel(level))
+	}
+	if c.DefaultFileName != "" {
+		opts = append(opts, SetDefaultFileName(c.DefaultFileName))
+	}
+	if c.FileOutput != nil {
+		opts = append(opts, EnableFileOutput(*c.FileOutput))
+	}
+	if c.ConsoleOutput != nil {
+		opts = append(opts, EnableConsoleOutput(*c.ConsoleOutput))
+	}
+	if c.AddSource != nil {
+		opts = append(opts, EnableSourceInfo(*c.AddSource))
+	}
+	if c.ParamFormat != "" {
+		formatter, err := parseParamFormat(c.ParamFormat)
+		if err != nil {
+			return nil, fmt.Errorf("param_format: %w", err)
+		}
+		opts = append(opts, SetParamFormatter(formatter))
+	}
+	if c.MaxFileHandles != 0 {
+		opts = append(opts, SetMaxFileHandles(c.MaxFileHandles))
+	}
+	if c.Mode != "" {
+		mode, err := parseMode(c.Mode)
+		if err != nil {
+			return nil, fmt.Errorf("mode: %w", err)
+		}
+		opts = append(opts, SetMode(mode))
+	}
+	if c.MaxFileSize != 0 {
+		opts = append(opts, SetMaxFileSize(c.MaxFileSize))
+	}
+	if c.MaxBackups != 0 {
+		opts = append(opts, SetMaxBackups(c.MaxBackups))
+	}
+	if c.DailyRotation != nil {
+		opts = append(opts, SetDailyRotation(*c.DailyRotation))
+	}
+
+	for name, spec := range c.LevelColors {
+		level, err := ParseLogLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("level_colors: %w", err)
+		}
+		attrs, err := ParseColor(spec)
+		if err != nil {
+			return nil, fmt.Errorf("level_colors: %s: %w", name, err)
+		}
+		opts = append(opts, SetLevelColor(level, attrs...))
+	}
+
+	return opts, nil
+}
+
+// Snapshot returns the current settings of the logger as a Config, e.g. to
+// restore them with Apply after temporary changes. A custom param formatter
+// leaves ParamFormat empty, but is still restored by Apply.
+func (l *Logger) Snapshot() Config {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	fileOutput, consoleOutput, addSource := root.OutputToFile, root.OutputToConsole, root.AddSource
+	root.fileMutex.Lock()
+	maxFileSize, maxBackups, dailyRotation := root.maxFileSize, root.maxBackups, root.dailyRotation
+	root.fileMutex.Unlock()
+	return Config{
+		BufferSize:      cap(root.LogChannel),
+		FileLevel:       levelName(root.FileLevel),
+		ConsoleLevel:    levelName(root.ConsoleLevel),
+		DefaultFileName: root.DefaultFileName,
+		FileOutput:      &fileOutput,
+		ConsoleOutput:   &consoleOutput,
+		AddSource:       &addSource,
+		ParamFormat:     paramFormatName(root.paramFormatter),
+		MaxFileHandles:  root.maxFileHandles,
+		Mode:            modeName(root.mode),
+		MaxFileSize:     maxFileSize,
+		MaxBackups:      maxBackups,
+		DailyRotation:   &dailyRotation,
+		LevelColors:     levelColorSpecs(root.levelColors),
+		paramFormatter:  root.paramFormatter,
+	}
+}
+
+// Apply changes the settings of a running logger to those of the config,
+// e.g. to restore a Snapshot. Zero values keep the current settings, and a
+// non-nil LevelColors replaces all custom colors. Lowering MaxFileHandles
+// closes the least recently used handles beyond the new limit. Rotation
+// settings apply from the next write. The buffer size and the mode cannot be
+// changed at runtime and must be empty or match the current ones.
+// The whole config is validated before anything is changed, so an invalid
+// config leaves the logger untouched. Apply waits for messages being formatted,
+// so it must not be called from log options, formatters or level adjusters.
+func (l *Logger) Apply(cfg Config) error {
+	root := l.root()
+	opts, err := cfg.Options()
+	if err != nil {
+		return err
+	}
+
+	// Validate the values on a scratch logger before changing anything
+	staged := &Logger{}
+	for _, opt := range opts {
+		if err := opt(staged); err != nil {
+			return err
+		}
+	}
+	if cfg.BufferSize != 0 && cfg.BufferSize != cap(root.LogChannel) {
+		return fmt.Errorf("buffer_size cannot be changed at runtime")
+	}
+	if cfg.Mode != "" && staged.mode != root.mode {
+		return fmt.Errorf("mode cannot be changed at runtime")
+	}
+
+	root.settingsMutex.Lock()
+	defer root.settingsMutex.Unlock()
+
+	if cfg.FileLevel != "" {
+		root.FileLevel = staged.FileLevel
+	}
+	if cfg.ConsoleLevel != "" {
+		root.ConsoleLevel = staged.ConsoleLevel
+	}
+	if cfg.DefaultFileName != "" {
+		root.DefaultFileName = staged.DefaultFileName
+	}
+	if cfg.FileOutput != nil {
+		root.OutputToFile = staged.OutputToFile
+	}
+	if cfg.ConsoleOutput != nil {
+		root.OutputToConsole = staged.OutputToConsole
+	}
+	if cfg.AddSource != nil {
+		root.AddSource = staged.AddSource
+	}
+	if cfg.ParamFormat != "" {
+		root.paramFormatter = staged.paramFormatter
+	} else if cfg.paramFormatter != nil {
+		root.paramFormatter = cfg.paramFormatter
+	}
+	root.fileMutex.Lock()
+	if cfg.MaxFileHandles != 0 {
+		root.maxFileHandles = staged.maxFileHandles
+		root.cleanupFileHandles()
+	}
+	if cfg.MaxFileSize != 0 {
+		// Files opened without a size limit have not been tracked yet
+		if root.maxFileSize == 0 {
+			root.trackFileSizesLocked()
+		}
+		root.maxFileSize = staged.maxFileSize
+	}
+	if cfg.MaxBackups != 0 {
+		root.maxBackups = staged.maxBackups
+	}
+	if cfg.DailyRotation != nil {
+		root.dailyRotation = staged.dailyRotation
+	}
+	root.fileMutex.Unlock()
+	if cfg.LevelColors != nil {
+		root.levelColors = staged.levelColors
+	}
+	return nil
+}
+
+// paramFormatName returns the config name of a built-in param formatter, or "" for others.
+func paramFormatName(formatter ParamFormatter) string {
+	switch reflect.ValueOf(formatter).Pointer() {
+	case reflect.ValueOf(FormatParamsAsKeyValue).Pointer():
+		return "keyvalue"
+	case reflect.ValueOf(FormatParamsAsJSON).Pointer():
+		return "json"
+	case reflect.ValueOf(FormatParamsAsLogfmt).Pointer():
+		return "logfmt"
+	default:
+		return ""
+	}
+}
+
+// modeName returns the config name of a dispatch mode.
+func modeName(mode Mode) string {
+	if mode == Synchronous {
+		return "sync"
+	}
+	return "async"
+}
+
+// levelName returns the config name of a level, parsed back by ParseLogLevel.
+// Levels without a name are given as their number.
+func levelName(level LogLevel) string {
+	if name := level.String(); name != "UNKNOWN" {
+		return strings.ToLower(name)
+	}
+	return strconv.Itoa(int(level))
+}
+
+// levelColorSpecs returns custom level colors as config color specifications.
+// The result is never nil, so applying it also removes colors added later.
+// Levels using attributes that ParseColor does not know are left out.
+func levelColorSpecs(levelColors map[LogLevel][]color.Attribute) map[string]string {
+	specs := make(map[string]string, len(levelColors))
+	for level, attrs := range levelColors {
+		names := make([]string, 0, len(attrs))
+		for _, attr := range attrs {
+			for name, named := range colorNames {
+				if named == attr {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+		// Colors without a name cannot be expressed in a config
+		if len(names) == len(attrs) {
+			specs[levelName(level)] = strings.Join(names, "+")
+		}
+	}
+	return specs
+}
+
+// parseParamFormat returns the parameter formatter matching the given name.
+func parseParamFormat(name string) (ParamFormatter, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "keyvalue", "kv":
+		return FormatParamsAsKeyValue, nil
+	case "json":
+		return FormatParamsAsJSON, nil
+	case "logfmt":
+		return FormatParamsAsLogfmt, nil
+	default:
+		return nil, fmt.Errorf("unknown param format: %q", name)
+	}
+}
+
+// parseMode returns the dispatch mode matching the given name.
+func parseMode(name string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "async", "asynchronous":
+		return Asynchronous, nil
+	case "sync", "synchronous":
+		return Synchronous, nil
+	default:
+		return Asynchronous, fmt.Errorf("unknown mode: %q", name)
+	}
+}
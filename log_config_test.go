@@ -0,0 +1,182 @@
+package asynclog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestConfigRotation(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		maxFileSize   int64
+		maxBackups    int
+		dailyRotation bool
+	}{
+		{"unset", `{}`, 0, 0, false},
+		{"size", `{"max_file_size": 1048576, "max_backups": 3}`, 1048576, 3, false},
+		{"daily", `{"max_backups": 7, "daily_rotation": true}`, 0, 7, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			if err := json.Unmarshal([]byte(tt.data), &cfg); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			l, err := NewLoggerFromConfig(cfg, EnableFileOutput(false), EnableConsoleOutput(false))
+			if err != nil {
+				t.Fatalf("NewLoggerFromConfig: %v", err)
+			}
+			defer l.Close()
+			if l.maxFileSize != tt.maxFileSize || l.maxBackups != tt.maxBackups || l.dailyRotation != tt.dailyRotation {
+				t.Errorf("rotation = (%d, %d, %v), want (%d, %d, %v)", l.maxFileSize, l.maxBackups, l.dailyRotation,
+					tt.maxFileSize, tt.maxBackups, tt.dailyRotation)
+			}
+		})
+	}
+}
+
+func TestApplyMaxFileSize(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous))
+	l.Info("before the limit")
+
+	// The file opened without a limit rotates once the limit is set
+	if err := l.Apply(Config{MaxFileSize: 40, MaxBackups: 1}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	l.Info("after the limit")
+
+	if got := readFile(t, file+".1"); !strings.Contains(got, "before the limit") {
+		t.Errorf("rotated file = %q, want the message logged before the limit", got)
+	}
+	if got := readFile(t, file); !strings.Contains(got, "after the limit") || strings.Contains(got, "before") {
+		t.Errorf("file = %q, want only the message logged after the limit", got)
+	}
+	if _, err := os.Stat(file + ".2"); !os.IsNotExist(err) {
+		t.Errorf("Stat(%s.2) error = %v, want the file pruned", file, err)
+	}
+}
+
+func TestApplyAddSource(t *testing.T) {
+	enabled, disabled := true, false
+	tests := []struct {
+		name    string
+		initial bool
+		value   *bool
+		want    bool
+	}{
+		{"unset keeps enabled", true, nil, true},
+		{"unset keeps disabled", false, nil, false},
+		{"disables", true, &disabled, false},
+		{"enables", false, &enabled, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, EnableSourceInfo(tt.initial))
+			if err := l.Apply(Config{ConsoleLevel: "debug", AddSource: tt.value}); err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if got := *l.Snapshot().AddSource; got != tt.want {
+				t.Errorf("AddSource = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyLowersMaxFileHandles(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetFileNameSanitizer(acceptFileName))
+	dir := filepath.Dir(file)
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		l.Info("message", SetLogFile(filepath.Join(dir, name)))
+	}
+
+	if err := l.Apply(Config{MaxFileHandles: 1}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	l.fileMutex.Lock()
+	open := len(l.fileHandles)
+	_, kept := l.fileHandles[filepath.Join(dir, "c.log")]
+	l.fileMutex.Unlock()
+	if open != 1 || !kept {
+		t.Errorf("%d handles open after lowering the limit, want only the most recent one", open)
+	}
+}
+
+func TestApplyInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown file level", Config{ConsoleLevel: "debug", FileLevel: "loud"}},
+		{"unknown param format", Config{ConsoleLevel: "debug", ParamFormat: "xml"}},
+		{"negative max file handles", Config{ConsoleLevel: "debug", MaxFileHandles: -1}},
+		{"unknown color", Config{ConsoleLevel: "debug", LevelColors: map[string]string{"error": "plaid"}}},
+		{"buffer size change", Config{ConsoleLevel: "debug", BufferSize: 7}},
+		{"mode change", Config{ConsoleLevel: "debug", Mode: "sync"}},
+		{"negative max file size", Config{ConsoleLevel: "debug", MaxFileSize: -1}},
+		{"negative max backups", Config{ConsoleLevel: "debug", MaxBackups: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetConsoleLevel(LogLevelError), EnableSourceInfo(true))
+			before := l.Snapshot()
+
+			if err := l.Apply(tt.cfg); err == nil {
+				t.Fatal("Apply accepted an invalid config")
+			}
+			after := l.Snapshot()
+			// Functions never compare equal, so leave the param formatters out
+			before.paramFormatter, after.paramFormatter = nil, nil
+			if !reflect.DeepEqual(after, before) {
+				t.Errorf("settings changed to %+v, want %+v", after, before)
+			}
+		})
+	}
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	l, _ := newTestLogger(t, EnableSourceInfo(true), SetParamFormatter(FormatParamsAsLogfmt),
+		SetMaxFileSize(1<<20), SetMaxBackups(3), SetDailyRotation(true))
+	saved := l.Snapshot()
+
+	disabled := false
+	if err := l.Apply(Config{FileLevel: "error", AddSource: &disabled, ParamFormat: "json",
+		MaxFileSize: 1 << 10, MaxBackups: 1, DailyRotation: &disabled}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if err := l.Apply(saved); err != nil {
+		t.Fatalf("Apply snapshot: %v", err)
+	}
+	restored := l.Snapshot()
+	if restored.FileLevel != saved.FileLevel || !*restored.AddSource || restored.ParamFormat != "logfmt" ||
+		restored.MaxFileSize != 1<<20 || restored.MaxBackups != 3 || !*restored.DailyRotation {
+		t.Errorf("restored %+v, want %+v", restored, saved)
+	}
+}
+
+func TestSnapshotApplyCustomLevel(t *testing.T) {
+	custom := LogLevel(42)
+	l, _ := newTestLogger(t, SetLevelColor(custom, color.FgRed, color.Bold),
+		SetLevelColor(LogLevelError, color.FgHiRed), SetFileLevel(custom))
+	saved := l.Snapshot()
+
+	if err := l.Apply(Config{FileLevel: "info", LevelColors: map[string]string{}}); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if err := l.Apply(saved); err != nil {
+		t.Fatalf("Apply snapshot: %v", err)
+	}
+	if l.FileLevel != custom {
+		t.Errorf("FileLevel = %v, want %d", l.FileLevel, custom)
+	}
+	want := map[LogLevel][]color.Attribute{custom: {color.FgRed, color.Bold}, LogLevelError: {color.FgHiRed}}
+	if !reflect.DeepEqual(l.levelColors, want) {
+		t.Errorf("level colors = %v, want %v", l.levelColors, want)
+	}
+}
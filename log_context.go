@@ -0,0 +1,62 @@
+package asynclog
+
+import "context"
+
+// ContextExtractor returns the params to log for a context, e.g. its request ID.
+type ContextExtractor func(ctx context.Context) map[string]interface{}
+
+// SetContextExtractor sets the function pulling params out of the context passed
+// to the ...Context methods and to the slog handler. Per-call params are applied
+// after them. Without an extractor, contexts add no params.
+func SetContextExtractor(extractor ContextExtractor) LoggerOption {
+	return func(l *Logger) error {
+		l.ctxExtractor = extractor
+		return nil
+	}
+}
+
+// contextParams returns an option adding the params extracted from ctx.
+// Cancelled contexts are still logged, as their values remain readable.
+func contextParams(ctx context.Context) LogOption {
+	return func(m *LogMessage) {
+		if ctx == nil || m.owner == nil || m.owner.ctxExtractor == nil {
+			return
+		}
+		for key, value := range m.owner.ctxExtractor(ctx) {
+			m.setParam(key, value)
+		}
+	}
+}
+
+// TraceContext logs a message at the Trace level with params extracted from ctx.
+func (l *Logger) TraceContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelTrace, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+}
+
+// DebugContext logs a message at the Debug level with params extracted from ctx.
+func (l *Logger) DebugContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelDebug, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+}
+
+// InfoContext logs a message at the Info level with params extracted from ctx,
+// e.g. the request ID of an HTTP handler, see SetContextExtractor.
+func (l *Logger) InfoContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelInfo, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+}
+
+// WarningContext logs a message at the Warning level with params extracted from ctx.
+func (l *Logger) WarningContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelWarning, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+}
+
+// ErrorContext logs a message at the Error level with params extracted from ctx.
+func (l *Logger) ErrorContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelError, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+}
+
+// FatalContext logs a message at the Fatal level with params extracted from ctx,
+// and exits the process like Fatal.
+func (l *Logger) FatalContext(ctx context.Context, message string, opts ...LogOption) {
+	l.log(LogLevelFatal, message, append([]LogOption{contextParams(ctx)}, opts...)...)
+	l.exitFatal()
+}
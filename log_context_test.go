@@ -0,0 +1,64 @@
+package asynclog
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// requestIDKey is the context key of the request ID in tests.
+type requestIDKey struct{}
+
+func TestContextMethods(t *testing.T) {
+	extractor := func(ctx context.Context) map[string]interface{} {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return map[string]interface{}{"request_id": id}
+		}
+		return nil
+	}
+	cancelled, cancel := context.WithCancel(context.WithValue(context.Background(), requestIDKey{}, "r-2"))
+	cancel()
+	tests := []struct {
+		name      string
+		extractor ContextExtractor
+		log       func(*Logger)
+		want      string
+		absent    string
+	}{
+		{"extracted value", extractor, func(l *Logger) {
+			l.InfoContext(context.WithValue(context.Background(), requestIDKey{}, "r-1"), "handled")
+		}, "INFO: handled\nrequest_id=r-1", ""},
+		{"every level", extractor, func(l *Logger) {
+			l.WarningContext(context.WithValue(context.Background(), requestIDKey{}, "r-1"), "slow")
+		}, "WARNING: slow\nrequest_id=r-1", ""},
+		{"cancelled context still logged", extractor, func(l *Logger) {
+			l.ErrorContext(cancelled, "aborted")
+		}, "ERROR: aborted\nrequest_id=r-2", ""},
+		{"per-call params applied after", extractor, func(l *Logger) {
+			l.InfoContext(context.WithValue(context.Background(), requestIDKey{}, "r-1"), "handled",
+				SetLogParams(map[string]interface{}{"request_id": "override", "status": 200}))
+		}, "request_id=override status=200", "r-1"},
+		{"no value in context", extractor, func(l *Logger) {
+			l.InfoContext(context.Background(), "handled")
+		}, "INFO: handled\n", "request_id"},
+		{"no extractor", nil, func(l *Logger) {
+			l.InfoContext(context.WithValue(context.Background(), requestIDKey{}, "r-1"), "handled")
+		}, "INFO: handled\n", "request_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetParamFormatter(FormatParamsAsLogfmt),
+				SetContextExtractor(tt.extractor))
+
+			tt.log(l)
+
+			got := readFile(t, file)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.absent != "" && strings.Contains(got, tt.absent) {
+				t.Errorf("file = %q, want it without %q", got, tt.absent)
+			}
+		})
+	}
+}
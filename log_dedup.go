@@ -0,0 +1,123 @@
+package asynclog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// deduper suppresses identical consecutive messages within a time window.
+type deduper struct {
+	mu      sync.Mutex    // Mutex for synchronizing the deduper state.
+	window  time.Duration // Time during which repeats of a message are suppressed.
+	key     string        // Level, logger name, target file and text of the current message.
+	first   LogMessage    // Current message, as logged first in the window.
+	repeats int           // Repeats suppressed in the current window.
+	gen     uint64        // Generation of the current window, to ignore stale timers.
+	timer   *time.Timer   // Timer closing the current window.
+	closed  bool          // Whether the deduper was flushed on Close, so no timer is armed.
+}
+
+// SetDedup suppresses repeats of a message logged again within window after
+// its first occurrence, e.g. during an error storm. Messages are identical
+// when their level, text, logger name and target file match; params are not
+// compared.
+// When the window closes, a single "last message repeated N times" line is
+// logged if repeats were suppressed, and a new window starts with the next
+// message. Unlike SetCollapseConsecutive it applies to every output.
+func SetDedup(window time.Duration) LoggerOption {
+	return func(l *Logger) error {
+		if window <= 0 {
+			return fmt.Errorf("dedup window must be positive")
+		}
+		l.dedup = &deduper{window: window}
+		return nil
+	}
+}
+
+// dedupRepeat reports whether the message repeats the current one within its
+// window and is suppressed. Otherwise it starts a new window with the message,
+// after logging the note for the repeats of the previous one. Once the logger
+// is closing, messages are no longer suppressed and no window is started.
+func (l *Logger) dedupRepeat(logMsg LogMessage) bool {
+	d := l.dedup
+	key := fmt.Sprint(logMsg.Level, "\x00", logMsg.Component, "\x00", logMsg.File, "\x00", logMsg.Message)
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return false
+	}
+	if d.timer != nil && d.key == key {
+		d.repeats++
+		d.mu.Unlock()
+		return true
+	}
+	note, ok := d.closeWindowLocked(l.now())
+	d.key = key
+	d.first = logMsg
+	d.gen++
+	gen := d.gen
+	d.timer = time.AfterFunc(d.window, func() { l.expireDedup(gen) })
+	d.mu.Unlock()
+
+	if ok {
+		l.dispatch(note)
+	}
+	return false
+}
+
+// expireDedup closes the window of the given generation, if still current,
+// and logs the note for its repeats.
+func (l *Logger) expireDedup(gen uint64) {
+	d := l.dedup
+	d.mu.Lock()
+	if d.gen != gen {
+		d.mu.Unlock()
+		return
+	}
+	note, ok := d.closeWindowLocked(l.now())
+	d.mu.Unlock()
+
+	if ok {
+		l.dispatch(note)
+	}
+}
+
+// flushDedup closes the current window on Close and logs the note for its
+// repeats. No window is started afterwards, so no timer outlives the logger.
+func (l *Logger) flushDedup() {
+	d := l.dedup
+	d.mu.Lock()
+	note, ok := d.closeWindowLocked(l.now())
+	d.closed = true
+	d.mu.Unlock()
+
+	if ok {
+		l.dispatch(note)
+	}
+}
+
+// closeWindowLocked ends the current window and returns the note for its
+// repeats, if any, logged at now. The caller must hold the deduper mutex.
+func (d *deduper) closeWindowLocked(now time.Time) (LogMessage, bool) {
+	if d.timer == nil {
+		return LogMessage{}, false
+	}
+	d.timer.Stop()
+	d.timer = nil
+	d.key = ""
+	if d.repeats == 0 {
+		return LogMessage{}, false
+	}
+
+	note := d.first
+	note.Message = fmt.Sprintf("last message repeated %d times", d.repeats)
+	note.Params = nil
+	note.Event = ""
+	note.Time = now.In(note.Time.Location())
+	note.alsoStderr = false
+	d.repeats = 0
+	d.first = LogMessage{}
+	return note, true
+}
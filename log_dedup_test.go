@@ -0,0 +1,113 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// dedupStamp is the timestamp of messages logged at dedupNow.
+const dedupStamp = "[2024/06/01 12:30:45] "
+
+// dedupNow is the frozen clock of the dedup tests.
+func dedupNow() time.Time { return time.Date(2024, 6, 1, 12, 30, 45, 0, time.UTC) }
+
+func TestSetDedup(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		close bool // Close the window with Close instead of waiting for it to expire.
+		want  string
+	}{
+		{"single message", 1, true, ""},
+		{"repeats flushed on close", 5, true, dedupStamp + "INFO: disk full\n" + dedupStamp + "INFO: last message repeated 4 times\n"},
+		{"repeats after window expires", 3, false, dedupStamp + "INFO: disk full\n" + dedupStamp + "INFO: last message repeated 2 times\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(dedupNow),
+				SetDedup(50*time.Millisecond))
+
+			for i := 0; i < tt.count; i++ {
+				l.Info("disk full")
+			}
+			if tt.close {
+				if err := l.Close(); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+			} else {
+				deadline := time.Now().Add(time.Second)
+				for !strings.Contains(readFile(t, file), "repeated") && time.Now().Before(deadline) {
+					time.Sleep(10 * time.Millisecond)
+				}
+			}
+
+			want := tt.want
+			if want == "" {
+				want = dedupStamp + "INFO: disk full\n"
+			}
+			if got := readFile(t, file); got != want {
+				t.Errorf("file = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDedupDifferentMessages(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(dedupNow), SetDedup(time.Minute))
+
+	l.Info("disk full")
+	l.Info("disk full")
+	l.Warning("disk full")
+	l.Info("disk full")
+
+	want := dedupStamp + "INFO: disk full\n" + dedupStamp + "INFO: last message repeated 1 times\n" +
+		dedupStamp + "WARNING: disk full\n" + dedupStamp + "INFO: disk full\n"
+	if got := readFile(t, file); got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestDedupNoTimerAfterClose(t *testing.T) {
+	l, _ := newTestLogger(t, SetMode(Synchronous), SetDedup(time.Minute))
+
+	// Log concurrently with Close, so some messages arrive after the flush
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("disk full")
+			}
+		}()
+	}
+	l.Close()
+	wg.Wait()
+	l.Info("disk full")
+
+	l.dedup.mu.Lock()
+	defer l.dedup.mu.Unlock()
+	if l.dedup.timer != nil {
+		t.Error("dedup timer armed after Close")
+	}
+}
+
+func TestDedupPerFile(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(dedupNow), SetDedup(time.Minute),
+		SetFileNameSanitizer(acceptFileName))
+	other := filepath.Join(filepath.Dir(file), "other.log")
+
+	l.Info("disk full")
+	l.Info("disk full", SetLogFile(other))
+	l.Info("disk full", SetLogFile(other))
+
+	if got, want := readFile(t, file), dedupStamp+"INFO: disk full\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+	if got, want := readFile(t, other), dedupStamp+"INFO: disk full\n"; got != want {
+		t.Errorf("other file = %q, want %q", got, want)
+	}
+}
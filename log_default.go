@@ -0,0 +1,110 @@
+package asynclog
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultLogger atomic.Pointer[Logger] // Logger used by the package-level functions.
+	defaultMutex  sync.Mutex             // Mutex for creating and replacing the default logger.
+)
+
+// Default returns the logger used by the package-level functions such as Info.
+// Unless replaced with SetDefault, it is created on first use and logs to the
+// console only, synchronously, so no message is lost when a short program
+// exits without closing it.
+func Default() *Logger {
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	l, err := NewLogger(EnableFileOutput(false), SetMode(Synchronous))
+	if err != nil {
+		panic(fmt.Sprintf("asynclog: failed to create default logger: %v", err))
+	}
+	defaultLogger.Store(l)
+	return l
+}
+
+// SetDefault makes l the logger used by the package-level functions.
+// The previous default logger is not closed. A nil l restores the built-in
+// default, which is created again on next use.
+func SetDefault(l *Logger) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+
+	defaultLogger.Store(l)
+}
+
+// Trace logs a message at the Trace level with the default logger.
+func Trace(message string, opts ...LogOption) {
+	Default().log(LogLevelTrace, message, opts...)
+}
+
+// Debug logs a message at the Debug level with the default logger.
+func Debug(message string, opts ...LogOption) {
+	Default().log(LogLevelDebug, message, opts...)
+}
+
+// Info logs a message at the Info level with the default logger.
+func Info(message string, opts ...LogOption) {
+	Default().log(LogLevelInfo, message, opts...)
+}
+
+// Warning logs a message at the Warning level with the default logger.
+func Warning(message string, opts ...LogOption) {
+	Default().log(LogLevelWarning, message, opts...)
+}
+
+// Error logs a message at the Error level with the default logger.
+func Error(message string, opts ...LogOption) {
+	Default().log(LogLevelError, message, opts...)
+}
+
+// Fatal logs a message at the Fatal level with the default logger, closes it
+// and exits the program with status 1.
+func Fatal(message string, opts ...LogOption) {
+	l := Default()
+	l.log(LogLevelFatal, message, opts...)
+	l.exitFatal()
+}
+
+// Tracef logs a message at the Trace level with the default logger, formatted like fmt.Sprintf.
+func Tracef(format string, args ...interface{}) {
+	Default().log(LogLevelTrace, fmt.Sprintf(format, args...))
+}
+
+// Debugf logs a message at the Debug level with the default logger, formatted like fmt.Sprintf.
+func Debugf(format string, args ...interface{}) {
+	Default().log(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Infof logs a message at the Info level with the default logger, formatted like fmt.Sprintf.
+func Infof(format string, args ...interface{}) {
+	Default().log(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warningf logs a message at the Warning level with the default logger, formatted like fmt.Sprintf.
+func Warningf(format string, args ...interface{}) {
+	Default().log(LogLevelWarning, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a message at the Error level with the default logger, formatted like fmt.Sprintf.
+func Errorf(format string, args ...interface{}) {
+	Default().log(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatalf logs a message at the Fatal level with the default logger, formatted
+// like fmt.Sprintf, closes it and exits the program with status 1.
+func Fatalf(format string, args ...interface{}) {
+	l := Default()
+	l.log(LogLevelFatal, fmt.Sprintf(format, args...))
+	l.exitFatal()
+}
@@ -0,0 +1,102 @@
+package asynclog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// useDefault makes a synchronous console-only logger writing to buf the
+// default logger until the end of the test.
+func useDefault(t *testing.T, buf *bytes.Buffer, opts ...LoggerOption) *Logger {
+	t.Helper()
+	base := []LoggerOption{SetMode(Synchronous), EnableFileOutput(false), EnableConsoleOutput(true),
+		SetConsoleWriter(buf), SetConsoleLevel(LogLevelTrace)}
+	l, err := NewLogger(append(base, opts...)...)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	previous := defaultLogger.Load()
+	SetDefault(l)
+	t.Cleanup(func() {
+		SetDefault(previous)
+		l.Close()
+	})
+	return l
+}
+
+func TestDefaultFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{"Trace", func() { Trace("started") }, "TRACE: started"},
+		{"Debug", func() { Debug("started") }, "DEBUG: started"},
+		{"Info", func() { Info("started", SetLogParams(map[string]interface{}{"port": 8080})) }, "INFO: started"},
+		{"Warning", func() { Warning("started") }, "WARNING: started"},
+		{"Error", func() { Error("started") }, "ERROR: started"},
+		{"Tracef", func() { Tracef("port %d", 8080) }, "TRACE: port 8080"},
+		{"Debugf", func() { Debugf("port %d", 8080) }, "DEBUG: port 8080"},
+		{"Infof", func() { Infof("port %d", 8080) }, "INFO: port 8080"},
+		{"Warningf", func() { Warningf("port %d", 8080) }, "WARNING: port 8080"},
+		{"Errorf", func() { Errorf("port %d", 8080) }, "ERROR: port 8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			useDefault(t, &buf)
+
+			tt.log()
+
+			if got := buf.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("console = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultFatal(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+		want string
+	}{
+		{"Fatal", func() { Fatal("stopped") }, "FATAL: stopped"},
+		{"Fatalf", func() { Fatalf("stopped after %d", 3) }, "FATAL: stopped after 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			code := -1
+			useDefault(t, &buf, SetExitFunc(func(c int) { code = c }))
+
+			tt.log()
+
+			if got := buf.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("console = %q, want it to contain %q", got, tt.want)
+			}
+			if code != 1 {
+				t.Errorf("exit code = %d, want 1", code)
+			}
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	var buf bytes.Buffer
+	l := useDefault(t, &buf)
+	if Default() != l {
+		t.Fatal("Default did not return the logger set with SetDefault")
+	}
+
+	// A nil logger restores the built-in default
+	SetDefault(nil)
+	builtin := Default()
+	if builtin == l || builtin == nil {
+		t.Fatalf("Default after SetDefault(nil) = %p, want a new built-in logger", builtin)
+	}
+	if Default() != builtin {
+		t.Error("Default created the built-in logger again")
+	}
+}
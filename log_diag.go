@@ -0,0 +1,45 @@
+package asynclog
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetLoggerName names the logger, to tell several loggers of a process apart.
+// The name prefixes the logger's own diagnostics, such as write failures,
+// as "[name] ", and is added as the "logger" param to heartbeat messages.
+func SetLoggerName(name string) LoggerOption {
+	return func(l *Logger) error {
+		l.loggerName = name
+		return nil
+	}
+}
+
+// SetErrorHandler sets a function receiving the logger's own errors, such as
+// failed file opens and writes, e.g. to forward them to monitoring.
+// The handler is called from the goroutine writing the message and should not
+// log through the same logger. By default the errors are printed to stderr.
+func SetErrorHandler(handler func(error)) LoggerOption {
+	return func(l *Logger) error {
+		if handler == nil {
+			return fmt.Errorf("error handler must not be nil")
+		}
+		l.errorHandler = handler
+		return nil
+	}
+}
+
+// diagf reports an internal diagnostic to the error handler, or prints it to
+// stderr, prefixed with the logger name, if set.
+func (l *Logger) diagf(format string, args ...interface{}) {
+	err := fmt.Errorf(format, args...)
+	if l.errorHandler != nil {
+		l.errorHandler(err)
+		return
+	}
+	if l.loggerName != "" {
+		fmt.Fprintf(os.Stderr, "[%s] %v\n", l.loggerName, err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
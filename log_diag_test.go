@@ -0,0 +1,133 @@
+package asynclog
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// errWriter is a writer failing every write with err.
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestSetErrorHandler(t *testing.T) {
+	errDisk := errors.New("disk full")
+	tests := []struct {
+		name    string
+		opts    func(t *testing.T) []LoggerOption
+		wantErr error
+		want    string
+	}{
+		{"failing writer", func(t *testing.T) []LoggerOption {
+			return []LoggerOption{SetFileWriter(errWriter{errDisk})}
+		}, errDisk, "Error writing to log writer"},
+		{"file cannot be opened", func(t *testing.T) []LoggerOption {
+			// A file where the log directory should be makes the open fail
+			blocker := filepath.Join(t.TempDir(), "blocker")
+			if err := os.WriteFile(blocker, nil, 0644); err != nil {
+				t.Fatalf("WriteFile: %v", err)
+			}
+			return []LoggerOption{SetDefaultFileName(filepath.Join(blocker, "app.log"))}
+		}, nil, "failed to create log directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var errs []error
+			handler := func(err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				errs = append(errs, err)
+			}
+			opts := append([]LoggerOption{SetMode(Synchronous), SetErrorHandler(handler)}, tt.opts(t)...)
+			l, _ := newTestLogger(t, opts...)
+
+			l.Info("lost")
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(errs) == 0 {
+				t.Fatal("error handler was not called")
+			}
+			if tt.wantErr != nil && !errors.Is(errs[0], tt.wantErr) {
+				t.Errorf("handler received %v, want it to wrap %v", errs[0], tt.wantErr)
+			}
+			if !strings.Contains(errs[0].Error(), tt.want) {
+				t.Errorf("handler received %q, want it to contain %q", errs[0], tt.want)
+			}
+			if got := l.WriteErrorCount(); got != 1 {
+				t.Errorf("WriteErrorCount = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestDiagfWithoutHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		loggerName string
+		want       string
+	}{
+		{"unnamed", "", "Failed to close log file: closed\n"},
+		{"named", "billing", "[billing] Failed to close log file: closed\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := captureStderr(t, func() {
+				l := &Logger{loggerName: tt.loggerName}
+				l.diagf("Failed to close log file: %w", errors.New("closed"))
+			})
+			if stderr != tt.want {
+				t.Errorf("stderr = %q, want %q", stderr, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetLoggerName(t *testing.T) {
+	// Two loggers of a process failing the same way are told apart by their names
+	stderr := captureStderr(t, func() {
+		for _, name := range []string{"access", "app"} {
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetLoggerName(name),
+				SetFileWriter(errWriter{errors.New("disk full")}))
+			l.Info("lost")
+		}
+	})
+
+	lines := strings.Split(strings.TrimSuffix(stderr, "\n"), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "[access] ") || !strings.HasPrefix(lines[1], "[app] ") {
+		t.Fatalf("stderr = %q, want one diagnostic per logger, prefixed with its name", lines)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "disk full") {
+			t.Errorf("diagnostic = %q, want the write error", line)
+		}
+	}
+}
+
+// captureStderr returns what fn prints to os.Stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe: %v", err)
+	}
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	fn()
+	w.Close()
+	var buf bytes.Buffer
+	buf.ReadFrom(r)
+	return buf.String()
+}
@@ -0,0 +1,87 @@
+package asynclog
+
+import (
+	"fmt"
+	"time"
+)
+
+// DurationFormat defines how time.Duration param values are rendered.
+type DurationFormat int
+
+const (
+	// DurationDefault leaves the choice to the formatter: JSON formatters render
+	// milliseconds, text formatters the String form such as "1h2m3s".
+	DurationDefault DurationFormat = iota
+
+	// DurationString renders durations in their String form, e.g. "1.5s".
+	DurationString
+
+	// DurationMillis renders durations as a number of milliseconds, e.g. 1500.
+	DurationMillis
+
+	// DurationSeconds renders durations as a number of seconds, e.g. 1.5.
+	DurationSeconds
+)
+
+// SetDurationFormat sets how time.Duration param values are rendered
+// by every formatter, overriding the formatter's own default.
+func SetDurationFormat(format DurationFormat) LoggerOption {
+	return func(l *Logger) error {
+		if format < DurationDefault || format > DurationSeconds {
+			return fmt.Errorf("unknown duration format: %d", format)
+		}
+		l.durationFormat = format
+		return nil
+	}
+}
+
+// formatDuration returns a duration in the given format.
+func formatDuration(d time.Duration, format DurationFormat) interface{} {
+	switch format {
+	case DurationMillis:
+		return float64(d) / float64(time.Millisecond)
+	case DurationSeconds:
+		return d.Seconds()
+	default:
+		return d.String()
+	}
+}
+
+// convertDurations returns the params with every time.Duration value, including
+// those in nested param maps, rendered in the given format.
+// The original map is left untouched.
+func convertDurations(params map[string]interface{}, format DurationFormat) map[string]interface{} {
+	converted, _ := convertDurationValues(params, format)
+	return converted
+}
+
+// convertDurationValues is convertDurations, also reporting whether any value was converted.
+func convertDurationValues(params map[string]interface{}, format DurationFormat) (map[string]interface{}, bool) {
+	var converted map[string]interface{}
+	for key, value := range params {
+		var newValue interface{}
+		if d, ok := value.(time.Duration); ok {
+			newValue = formatDuration(d, format)
+		} else if nested, ok := nestedParams(value); ok {
+			group, changed := convertDurationValues(nested, format)
+			if !changed {
+				continue
+			}
+			newValue = renest(value, group)
+		} else {
+			continue
+		}
+
+		if converted == nil {
+			converted = make(map[string]interface{}, len(params))
+			for k, v := range params {
+				converted[k] = v
+			}
+		}
+		converted[key] = newValue
+	}
+	if converted == nil {
+		return params, false
+	}
+	return converted, true
+}
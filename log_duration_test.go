@@ -0,0 +1,79 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	subSecond := 250 * time.Millisecond
+	multiHour := 26*time.Hour + 3*time.Minute + 4*time.Second
+	tests := []struct {
+		name   string
+		d      time.Duration
+		format DurationFormat
+		want   interface{}
+	}{
+		{"sub-second string", subSecond, DurationString, "250ms"},
+		{"sub-second millis", subSecond, DurationMillis, 250.0},
+		{"sub-second seconds", subSecond, DurationSeconds, 0.25},
+		{"multi-hour string", multiHour, DurationString, "26h3m4s"},
+		{"multi-hour millis", multiHour, DurationMillis, 93784000.0},
+		{"multi-hour seconds", multiHour, DurationSeconds, 93784.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatDuration(tt.d, tt.format); got != tt.want {
+				t.Errorf("formatDuration(%v) = %v, want %v", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDurationFormat(t *testing.T) {
+	params := map[string]interface{}{
+		"short": 1500 * time.Microsecond,
+		"long":  2*time.Hour + 30*time.Minute,
+	}
+	tests := []struct {
+		name   string
+		format DurationFormat
+		want   []string
+	}{
+		{"text default", DurationDefault, []string{`"short": 1.5ms`, `"long": 2h30m0s`}},
+		{"millis", DurationMillis, []string{`"short": 1.5`, `"long": 9e+06`}},
+		{"seconds", DurationSeconds, []string{`"short": 0.0015`, `"long": 9000`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetDurationFormat(tt.format))
+			l.Info("done", SetLogParams(params))
+
+			got := readFile(t, file)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatParamsAsJSONRendersMillis(t *testing.T) {
+	got := FormatParamsAsJSON(map[string]interface{}{
+		"short": 250 * time.Millisecond,
+		"long":  3 * time.Hour,
+	})
+	for _, want := range []string{`"short": 250`, `"long": 10800000`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FormatParamsAsJSON = %s, want it to contain %s", got, want)
+		}
+	}
+}
+
+func TestSetDurationFormatRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewLogger(SetDurationFormat(DurationFormat(42))); err == nil {
+		t.Error("NewLogger accepted an unknown duration format")
+	}
+}
@@ -0,0 +1,52 @@
+package asynclog
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// fatalCallbackTimeout bounds how long Fatal waits for level callbacks before exiting.
+const fatalCallbackTimeout = time.Second
+
+// SetExitFunc sets the function Fatal calls to exit the process, os.Exit by
+// default. Tests can replace it to observe the exit instead.
+func SetExitFunc(exit func(int)) LoggerOption {
+	return func(l *Logger) error {
+		if exit == nil {
+			return fmt.Errorf("exit func must not be nil")
+		}
+		l.exitFunc = exit
+		return nil
+	}
+}
+
+// exitFatal closes the logger, so the fatal message and all messages queued
+// before it are written and flushed, and exits the process with status 1.
+// Level callbacks started for the message get a short time to finish.
+func (l *Logger) exitFatal() {
+	root := l.root()
+	if err := root.Close(); err != nil {
+		root.diagf("Failed to close logger: %w", err)
+	}
+	root.waitCallbacks(fatalCallbackTimeout)
+
+	exit := root.exitFunc
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(1)
+}
+
+// waitCallbacks waits until running level callbacks have returned or the timeout has passed.
+func (l *Logger) waitCallbacks(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		l.callbacks.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -0,0 +1,49 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFatal(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exits []int
+			l, file := newTestLogger(t, SetMode(tt.mode), SetExitFunc(func(code int) { exits = append(exits, code) }))
+			var callbacks int
+			l.OnLevel(LogLevelFatal, func(LogMessage) { callbacks++ })
+
+			l.Info("before")
+			l.Fatal("giving up")
+
+			if len(exits) != 1 || exits[0] != 1 {
+				t.Fatalf("exit calls = %v, want a single exit with status 1", exits)
+			}
+			// The logger is closed before exiting, so everything is on disk
+			got := readFile(t, file)
+			if !strings.Contains(got, "INFO: before\n") || !strings.HasSuffix(got, "FATAL: giving up\n") {
+				t.Errorf("file = %q, want the queued and the fatal message", got)
+			}
+			if callbacks != 1 {
+				t.Errorf("fatal callbacks ran %d times, want 1", callbacks)
+			}
+			l.Info("after")
+			if got := readFile(t, file); strings.Contains(got, "after") {
+				t.Errorf("file = %q, want messages after Fatal dropped", got)
+			}
+		})
+	}
+}
+
+func TestSetExitFuncRejectsNil(t *testing.T) {
+	if _, err := NewLogger(SetExitFunc(nil)); err == nil {
+		t.Error("NewLogger accepted a nil exit func")
+	}
+}
@@ -0,0 +1,63 @@
+package asynclog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// FileNameSanitizer validates or rewrites a per-message log file name
+// before the file is opened. It returns an error to reject the name.
+type FileNameSanitizer func(name string) (string, error)
+
+// SetFileNameSanitizer sets the function applied to per-message log file names
+// (set with SetLogFile) before they are opened. The configured default file name
+// is trusted and not sanitized. DefaultFileNameSanitizer is used by default.
+func SetFileNameSanitizer(sanitizer FileNameSanitizer) LoggerOption {
+	return func(l *Logger) error {
+		if sanitizer == nil {
+			return fmt.Errorf("file name sanitizer must not be nil")
+		}
+		l.sanitizeName = sanitizer
+		return nil
+	}
+}
+
+// DefaultFileNameSanitizer cleans a file name and rejects names that could
+// escape the working directory: absolute paths, paths climbing out with "..",
+// and names containing control or otherwise illegal characters.
+func DefaultFileNameSanitizer(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("empty log file name")
+	}
+	illegal := "\x00"
+	if runtime.GOOS == "windows" {
+		illegal += `<>:"|?*`
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(illegal, r) {
+			return "", fmt.Errorf("illegal character %q in log file name %q", r, name)
+		}
+	}
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("log file name %q escapes the log directory", name)
+	}
+	return filepath.Clean(name), nil
+}
+
+// resolveFileName returns the file a message should be written to.
+// Rejected per-message names fall back to the default file so the message is not lost.
+// The caller must hold settingsMutex for reading.
+func (l *Logger) resolveFileName(name string) string {
+	defaultFile := l.DefaultFileName
+	if name == "" || name == defaultFile {
+		return defaultFile
+	}
+	sanitized, err := l.sanitizeName(name)
+	if err != nil {
+		l.diagf("Rejected log file name: %w", err)
+		return defaultFile
+	}
+	return sanitized
+}
@@ -0,0 +1,69 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultFileNameSanitizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"clean relative", "app.log", "app.log", false},
+		{"nested relative", "logs/jobs/app.log", filepath.Join("logs", "jobs", "app.log"), false},
+		{"cleaned", "./logs//app.log", filepath.Join("logs", "app.log"), false},
+		{"inner dot-dot", "logs/../app.log", "app.log", false},
+		{"parent", "../x", "", true},
+		{"climbs out", "a/../../x", "", true},
+		{"absolute", filepath.Join(string(filepath.Separator), "tmp", "x.log"), "", true},
+		{"empty", "", "", true},
+		{"control character", "app\n.log", "", true},
+		{"nul", "app\x00.log", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DefaultFileNameSanitizer(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultFileNameSanitizer(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("DefaultFileNameSanitizer(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRejectedFileNameFallsBackToDefault(t *testing.T) {
+	var diags []string
+	l, file := newTestLogger(t, SetMode(Synchronous),
+		SetErrorHandler(func(err error) { diags = append(diags, err.Error()) }))
+
+	l.Info("escaped", SetLogFile("../escaped.log"))
+
+	if got := readFile(t, file); !strings.Contains(got, "INFO: escaped") {
+		t.Errorf("default file = %q, want the message of the rejected name", got)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0], "escapes the log directory") {
+		t.Errorf("diagnostics = %v, want one for the rejected name", diags)
+	}
+}
+
+func TestSetFileNameSanitizer(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := newTestLogger(t, SetMode(Synchronous), SetFileNameSanitizer(func(name string) (string, error) {
+		return filepath.Join(dir, strings.ToLower(filepath.Base(name))), nil
+	}))
+
+	l.Info("job done", SetLogFile("Jobs.LOG"))
+
+	if got := readFile(t, filepath.Join(dir, "jobs.log")); !strings.Contains(got, "INFO: job done") {
+		t.Errorf("sanitized file = %q, want the message", got)
+	}
+	if _, err := NewLogger(SetFileNameSanitizer(nil)); err == nil {
+		t.Error("NewLogger accepted a nil sanitizer")
+	}
+}
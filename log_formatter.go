@@ -4,13 +4,81 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/fatih/color"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
+// TruncatedParamsKey is the param key of the marker replacing params beyond the SetMaxParams limit.
+const TruncatedParamsKey = "_truncated"
+
+// renderParams returns params as they should be formatted.
+func (l *Logger) renderParams(params map[string]interface{}) map[string]interface{} {
+	handler := l.valueHandler
+	if handler == nil {
+		handler = DefaultUnsupportedValueHandler
+	}
+	params = replaceUnsupportedValues(params, handler)
+	if l.durationFormat != DurationDefault {
+		params = convertDurations(params, l.durationFormat)
+	}
+	if l.normalizeTimes {
+		params = normalizeTimeParams(params, l.timeLocation())
+	}
+	if l.maxParams > 0 && len(params) > l.maxParams {
+		params = limitParams(params, l.maxParams)
+	}
+	return params
+}
+
+// normalizeTimeParams returns the params with every time.Time value converted to loc.
+// The original map is left untouched.
+func normalizeTimeParams(params map[string]interface{}, loc *time.Location) map[string]interface{} {
+	var normalized map[string]interface{}
+	for key, value := range params {
+		t, ok := value.(time.Time)
+		if !ok {
+			continue
+		}
+		if normalized == nil {
+			normalized = make(map[string]interface{}, len(params))
+			for k, v := range params {
+				normalized[k] = v
+			}
+		}
+		normalized[key] = t.In(loc)
+	}
+	if normalized == nil {
+		return params
+	}
+	return normalized
+}
+
+// limitParams keeps the first n params in key order and adds a marker counting the rest.
+func limitParams(params map[string]interface{}, n int) map[string]interface{} {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	limited := make(map[string]interface{}, n+1)
+	for _, key := range keys[:n] {
+		limited[key] = params[key]
+	}
+	limited[TruncatedParamsKey] = fmt.Sprintf("...and %d more", len(keys)-n)
+	return limited
+}
+
 // formatLogLevel formats the log level string with optional color and bold styling.
+// The caller decides whether console output is colored at all.
 func formatLogLevel(text string, level LogLevel, bold bool) string {
 	colorAttr := getColorAttribute(level)
 	formatter := color.New(colorAttr)
+	formatter.EnableColor()
 	if bold {
 		formatter = formatter.Add(color.Bold)
 	}
@@ -42,7 +110,9 @@ func formatParamsWithColor(params string) string {
 	if params == "" {
 		return ""
 	}
-	return color.New(color.Faint).SprintfFunc()(params)
+	formatter := color.New(color.Faint)
+	formatter.EnableColor()
+	return formatter.SprintfFunc()(params)
 }
 
 // String returns a string representation of the log level.
@@ -60,29 +130,182 @@ func (level LogLevel) String() string {
 		return "ERROR"
 	case LogLevelFatal:
 		return "FATAL"
+	case LogLevelOff:
+		return "OFF"
 	default:
 		return "UNKNOWN"
 	}
 }
 
+// ParseLogLevel parses a case-insensitive level name such as "info" or "WARNING".
+// Levels without a name are given as their number, e.g. "10".
+func ParseLogLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "TRACE":
+		return LogLevelTrace, nil
+	case "DEBUG":
+		return LogLevelDebug, nil
+	case "INFO":
+		return LogLevelInfo, nil
+	case "WARNING", "WARN":
+		return LogLevelWarning, nil
+	case "ERROR":
+		return LogLevelError, nil
+	case "FATAL":
+		return LogLevelFatal, nil
+	case "OFF":
+		return LogLevelOff, nil
+	default:
+		if n, err := strconv.Atoi(strings.TrimSpace(name)); err == nil {
+			return LogLevel(n), nil
+		}
+		return LogLevelInfo, fmt.Errorf("unknown log level: %q", name)
+	}
+}
+
+// FormatLineAsText formats a log message in the default text layout,
+// with params formatted as key-value pairs on the following lines.
+func FormatLineAsText(m LogMessage) string {
+	line := fmt.Sprintf("[%s]", m.Time.Format(DefaultTimeFormat))
+	if m.Source != "" {
+		line += "[" + m.Source + "]"
+	}
+	line += fmt.Sprintf(" %s: %s", m.Level.String(), m.displayMessage())
+	if params := FormatParamsAsKeyValue(m.displayParams()); params != "" {
+		line += "\n" + params
+	}
+	return line
+}
+
 // FormatParamsAsKeyValue formats parameters as key-value pairs.
+// Groups are flattened into dotted keys, e.g. "http.method".
+// Nil values are rendered as null, like in JSON, to tell them apart from the string "<nil>".
+// Keys are sorted, so the same params always produce the same output.
 func FormatParamsAsKeyValue(params map[string]interface{}) string {
 	if len(params) == 0 {
 		return "" // Return empty string if no parameters
 	}
+	flat := flattenParams(params)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
 	var builder strings.Builder
-	for key, value := range params {
-		builder.WriteString(fmt.Sprintf("  \"%s\": %v\n", key, value))
+	for _, key := range keys {
+		builder.WriteString(fmt.Sprintf("  \"%s\": %s\n", key, formatKeyValue(flat[key])))
 	}
 
 	return strings.TrimSuffix(builder.String(), "\n")
 }
 
+// FormatParamsAsLogfmt formats parameters as space-separated logfmt pairs,
+// e.g. `method=GET path="/search?q=a b"`. Keys are sorted, groups are
+// flattened into dotted keys, and values containing spaces, equals signs,
+// quotes or control characters are quoted.
+func FormatParamsAsLogfmt(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return "" // Return empty string if no parameters
+	}
+	flat := flattenParams(params)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = logfmtKey(key) + "=" + logfmtValue(formatKeyValue(flat[key]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// logfmtKey replaces the characters a logfmt key cannot contain with underscores.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue quotes a logfmt value if it would otherwise be ambiguous.
+func logfmtValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == 0x7f || !unicode.IsPrint(r) {
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
+
+// formatKeyValue formats a single param value for the key-value formatter.
+func formatKeyValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case fmt.Stringer, error:
+		return fmt.Sprintf("%v", v)
+	}
+	// Nil pointers would otherwise print as <nil>
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "null"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// flattenParams flattens groups into a single map with dotted keys, e.g. the
+// group "http" holding {"method": "GET"} becomes {"http.method": "GET"}.
+// Maps logged as param values are kept as they are.
+func flattenParams(params map[string]interface{}) map[string]interface{} {
+	nested := false
+	for _, value := range params {
+		if _, ok := value.(paramGroup); ok {
+			nested = true
+			break
+		}
+	}
+	if !nested {
+		return params
+	}
+
+	flat := make(map[string]interface{}, len(params))
+	var flatten func(prefix string, params map[string]interface{})
+	flatten = func(prefix string, params map[string]interface{}) {
+		for key, value := range params {
+			if group, ok := value.(paramGroup); ok {
+				flatten(prefix+key+".", group)
+				continue
+			}
+			flat[prefix+key] = value
+		}
+	}
+	flatten("", params)
+	return flat
+}
+
 // FormatParamsAsJSON formats parameters as a JSON string.
+// Values JSON cannot encode, such as funcs and channels, are rendered
+// by DefaultUnsupportedValueHandler instead of failing the whole block.
+// Durations are rendered as milliseconds unless SetDurationFormat says otherwise.
+// Keys are sorted, as encoding/json does for maps.
 func FormatParamsAsJSON(params map[string]interface{}) string {
 	if len(params) == 0 {
 		return "" // Return empty string if no parameters
 	}
+	params = replaceUnsupportedValues(params, DefaultUnsupportedValueHandler)
+	params = convertDurations(params, DurationMillis)
 	jsonBytes, err := json.MarshalIndent(params, "", "  ")
 	if err != nil {
 		return fmt.Sprintf("Error formatting params: %v", err)
@@ -0,0 +1,179 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParamFormattersSortKeys(t *testing.T) {
+	params := map[string]interface{}{
+		"zone": "eu", "alpha": 1, "method": "GET", "beta": true, "path": "/", "id": 42,
+	}
+	tests := []struct {
+		name      string
+		formatter ParamFormatter
+		want      string
+	}{
+		{"key-value", FormatParamsAsKeyValue,
+			"  \"alpha\": 1\n  \"beta\": true\n  \"id\": 42\n  \"method\": GET\n  \"path\": /\n  \"zone\": eu"},
+		{"logfmt", FormatParamsAsLogfmt, "alpha=1 beta=true id=42 method=GET path=/ zone=eu"},
+		{"json", FormatParamsAsJSON,
+			"{\n  \"alpha\": 1,\n  \"beta\": true,\n  \"id\": 42,\n  \"method\": \"GET\",\n  \"path\": \"/\",\n  \"zone\": \"eu\"\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Map iteration order varies, so repeated calls would expose unsorted keys
+			for i := 0; i < 20; i++ {
+				if got := tt.formatter(params); got != tt.want {
+					t.Fatalf("call %d = %q, want %q", i, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatParamsAsLogfmt(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"plain values", map[string]interface{}{"method": "GET", "status": 200}, "method=GET status=200"},
+		{"spaces", map[string]interface{}{"path": "/search?q=a b"}, `path="/search?q=a b"`},
+		{"equals sign", map[string]interface{}{"query": "a=b"}, `query="a=b"`},
+		{"quotes", map[string]interface{}{"said": `he said "hi"`}, `said="he said \"hi\""`},
+		{"newline", map[string]interface{}{"text": "line1\nline2"}, `text="line1\nline2"`},
+		{"tab", map[string]interface{}{"text": "a\tb"}, `text="a\tb"`},
+		{"empty value", map[string]interface{}{"user": ""}, `user=""`},
+		{"nil value", map[string]interface{}{"user": nil}, "user=null"},
+		{"key with space", map[string]interface{}{"user name": "bob"}, "user_name=bob"},
+		{"group", map[string]interface{}{"http": paramGroup{"method": "GET"}}, "http.method=GET"},
+		{"no params", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatParamsAsLogfmt(tt.params); got != tt.want {
+				t.Errorf("FormatParamsAsLogfmt = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    LogLevel
+		wantErr bool
+	}{
+		{"info", LogLevelInfo, false},
+		{" WARN ", LogLevelWarning, false},
+		{"Off", LogLevelOff, false},
+		{"42", LogLevel(42), false},
+		{"-1", LogLevel(-1), false},
+		{"loud", LogLevelInfo, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLogLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLogLevel error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLogLevel = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMaxParams(t *testing.T) {
+	params := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4, "e": 5}
+	tests := []struct {
+		name string
+		opts []LoggerOption
+	}{
+		{"key-value", []LoggerOption{SetParamFormatter(FormatParamsAsKeyValue)}},
+		{"json", []LoggerOption{SetParamFormatter(FormatParamsAsJSON)}},
+		{"logfmt", []LoggerOption{SetParamFormatter(FormatParamsAsLogfmt)}},
+		{"structured json", []LoggerOption{SetStructuredJSON(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, append([]LoggerOption{SetMode(Synchronous), SetMaxParams(2)}, tt.opts...)...)
+
+			l.Info("many", SetLogParams(params))
+			l.Info("few", SetLogParams(map[string]interface{}{"x": 1, "y": 2}))
+
+			got := readFile(t, file)
+			if !strings.Contains(got, TruncatedParamsKey) || !strings.Contains(got, "...and 3 more") {
+				t.Errorf("file = %q, want the truncation marker", got)
+			}
+			if strings.Count(got, TruncatedParamsKey) != 1 {
+				t.Errorf("file = %q, want the marker only for the message over the limit", got)
+			}
+			for _, rendered := range []string{`"c"`, `"d"`, `"e"`, "c=3", "d=4", "e=5"} {
+				if strings.Contains(got, rendered) {
+					t.Errorf("file = %q, want the params beyond the limit dropped, found %s", got, rendered)
+				}
+			}
+		})
+	}
+}
+
+func TestSetMaxParamsRejectsNonPositive(t *testing.T) {
+	if _, err := NewLogger(SetMaxParams(0)); err == nil {
+		t.Error("NewLogger accepted a limit of 0")
+	}
+}
+
+func TestSetNormalizeTimeParams(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, tokyo)
+	tests := []struct {
+		name      string
+		normalize bool
+		want      string
+	}{
+		{"normalized", true, `"at": "2024-06-01T03:00:00Z"`},
+		{"kept", false, `"at": "2024-06-01T12:00:00+09:00"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetParamFormatter(FormatParamsAsJSON),
+				SetNormalizeTimeParams(tt.normalize))
+			params := map[string]interface{}{"at": at}
+
+			l.Info("scheduled", SetLogParams(params))
+
+			if got := readFile(t, file); !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			if params["at"] != at {
+				t.Errorf("caller's params changed to %v", params)
+			}
+		})
+	}
+}
+
+func TestNullAndBoolParams(t *testing.T) {
+	var user *struct{ Name string }
+	params := map[string]interface{}{"a_nil": nil, "b_nil_pointer": user, "c_on": true, "d_off": false}
+	tests := []struct {
+		name      string
+		formatter ParamFormatter
+		want      string
+	}{
+		{"key-value", FormatParamsAsKeyValue,
+			"  \"a_nil\": null\n  \"b_nil_pointer\": null\n  \"c_on\": true\n  \"d_off\": false"},
+		{"logfmt", FormatParamsAsLogfmt, "a_nil=null b_nil_pointer=null c_on=true d_off=false"},
+		{"json", FormatParamsAsJSON,
+			"{\n  \"a_nil\": null,\n  \"b_nil_pointer\": null,\n  \"c_on\": true,\n  \"d_off\": false\n}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter(params); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package asynclog
+
+import "fmt"
+
+// SetReopenOnHangup is not supported on this platform and always returns an error.
+// Call ReopenFiles directly instead.
+func SetReopenOnHangup(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		return fmt.Errorf("reopening on SIGHUP is not supported on this platform")
+	}
+}
+
+// watchHangup is a no-op on platforms without SIGHUP.
+func watchHangup(reopen func()) func() {
+	return func() {}
+}
@@ -0,0 +1,42 @@
+//go:build unix
+
+package asynclog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// SetReopenOnHangup makes the logger call ReopenFiles whenever the process
+// receives SIGHUP, as logrotate and similar tools expect after rotating files.
+func SetReopenOnHangup(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.reopenOnHangup = enable
+		return nil
+	}
+}
+
+// watchHangup calls reopen whenever the process receives SIGHUP.
+// It returns a function that stops watching.
+func watchHangup(reopen func()) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				reopen()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
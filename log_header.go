@@ -0,0 +1,87 @@
+package asynclog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// headerPart is a piece of a header template: literal text or a placeholder field.
+type headerPart struct {
+	text  string // Literal text, used when field is empty.
+	field string // Placeholder name without braces, e.g. "time".
+}
+
+// headerFields are the placeholders supported by header templates.
+var headerFields = map[string]bool{
+	"time":    true,
+	"level":   true,
+	"source":  true,
+	"message": true,
+}
+
+// SetHeaderTemplate sets the layout of the first line of text file records,
+// in place of the default "[{time}][{source}] {level}: {message}".
+// The placeholders {time}, {level}, {source} and {message} are replaced by the
+// timestamp, the level name, the caller's file:line (empty unless source info
+// is enabled) and the message; all other text is kept as is, e.g.
+//
+//	SetHeaderTemplate("{time} {level} {source} | {message}")
+//
+// Params follow on the next lines as usual. File formatters set with
+// SetFileFormatter take precedence over the template.
+func SetHeaderTemplate(template string) LoggerOption {
+	return func(l *Logger) error {
+		parts, err := parseHeaderTemplate(template)
+		if err != nil {
+			return err
+		}
+		l.headerParts = parts
+		return nil
+	}
+}
+
+// parseHeaderTemplate splits a header template into literal text and placeholders.
+func parseHeaderTemplate(template string) ([]headerPart, error) {
+	var parts []headerPart
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			parts = append(parts, headerPart{text: template})
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in header template")
+		}
+		field := template[start+1 : start+end]
+		if !headerFields[field] {
+			return nil, fmt.Errorf("unknown header template placeholder: {%s}", field)
+		}
+		if start > 0 {
+			parts = append(parts, headerPart{text: template[:start]})
+		}
+		parts = append(parts, headerPart{field: field})
+		template = template[start+end+1:]
+	}
+	return parts, nil
+}
+
+// renderHeader renders the header template for a message.
+func (l *Logger) renderHeader(logMsg LogMessage, timestamp string) string {
+	var builder strings.Builder
+	for _, part := range l.headerParts {
+		switch part.field {
+		case "time":
+			builder.WriteString(timestamp)
+		case "level":
+			builder.WriteString(logMsg.Level.String())
+		case "source":
+			builder.WriteString(logMsg.Source)
+		case "message":
+			builder.WriteString(logMsg.displayMessage())
+		default:
+			builder.WriteString(part.text)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,51 @@
+package asynclog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetHeaderTemplate(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		want     string // %s is replaced by the source.
+	}{
+		{"reordered with a separator", "{time} {level} {source} | {message}", "2024/06/01 12:00:00 WARNING %s | disk low"},
+		{"without brackets", "{level}: {message} ({time})", "WARNING: disk low (2024/06/01 12:00:00)"},
+		{"prefix", ">> {message}", ">> disk low"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), EnableSourceInfo(true),
+				SetClock(func() time.Time { return at }), SetHeaderTemplate(tt.template))
+
+			l.Warning("disk low", SetLogParams(map[string]interface{}{"free": "2%"}))
+			source := fmt.Sprintf("log_header_test.go:%d", thisLine()-1)
+
+			lines := strings.Split(readFile(t, file), "\n")
+			want := tt.want
+			if strings.Contains(want, "%s") {
+				want = fmt.Sprintf(want, source)
+			}
+			if lines[0] != want {
+				t.Errorf("header = %q, want %q", lines[0], want)
+			}
+			// Params still follow on the next line
+			if len(lines) < 2 || !strings.Contains(lines[1], `"free": 2%`) {
+				t.Errorf("lines = %q, want the params after the header", lines)
+			}
+		})
+	}
+}
+
+func TestSetHeaderTemplateRejectsInvalidTemplates(t *testing.T) {
+	for _, template := range []string{"{time} {level", "{time} {lvl}: {message}"} {
+		if _, err := NewLogger(SetHeaderTemplate(template)); err == nil {
+			t.Errorf("NewLogger accepted the header template %q", template)
+		}
+	}
+}
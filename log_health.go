@@ -0,0 +1,87 @@
+package asynclog
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultDegradeThreshold is the number of consecutive failures after which a file is degraded.
+	DefaultDegradeThreshold = 3
+
+	// DefaultDegradeCooldown is how long a degraded file is skipped before it is probed again.
+	DefaultDegradeCooldown = time.Minute
+)
+
+// fileHealth tracks consecutive failures of a single log file.
+type fileHealth struct {
+	failures      int       // Consecutive open or write failures.
+	degradedUntil time.Time // End of the current cooldown, zero if not degraded.
+}
+
+// isDegraded reports whether writes to the file are currently skipped.
+// Once the cooldown has passed, the next write is let through as a probe.
+// The caller must hold fileMutex.
+func (l *Logger) isDegraded(filename string) bool {
+	health, ok := l.fileHealth[filename]
+	if !ok || health.degradedUntil.IsZero() || !l.now().Before(health.degradedUntil) {
+		return false
+	}
+	l.degradedDrops.Add(1)
+	return true
+}
+
+// fileFailed records a failed open or write of a file. The first failures are
+// reported individually; once DefaultDegradeThreshold consecutive failures
+// have occurred, the file is degraded with a single warning, and failed
+// probes after each cooldown silently extend the degraded state.
+// The caller must hold fileMutex.
+func (l *Logger) fileFailed(filename string, err error) {
+	l.recordWriteError(err)
+
+	health, ok := l.fileHealth[filename]
+	if !ok {
+		health = &fileHealth{}
+		l.fileHealth[filename] = health
+	}
+	health.failures++
+
+	switch {
+	case !health.degradedUntil.IsZero():
+		health.degradedUntil = l.now().Add(DefaultDegradeCooldown)
+	case health.failures >= DefaultDegradeThreshold:
+		health.degradedUntil = l.now().Add(DefaultDegradeCooldown)
+		l.diagf("Log file %s degraded after %d consecutive failures, retrying every %s: %w",
+			filename, health.failures, DefaultDegradeCooldown, err)
+	default:
+		l.diagf("%w", err)
+	}
+}
+
+// fileSucceeded clears the failure state of a file after a successful write.
+// The caller must hold fileMutex.
+func (l *Logger) fileSucceeded(filename string) {
+	health, ok := l.fileHealth[filename]
+	if !ok {
+		return
+	}
+	if !health.degradedUntil.IsZero() {
+		l.diagf("Log file %s recovered", filename)
+	}
+	delete(l.fileHealth, filename)
+}
+
+// degradedFiles returns the sorted names of the currently degraded files.
+func (l *Logger) degradedFiles() []string {
+	l.fileMutex.Lock()
+	defer l.fileMutex.Unlock()
+
+	var files []string
+	for filename, health := range l.fileHealth {
+		if !health.degradedUntil.IsZero() {
+			files = append(files, filename)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,86 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDegradedFile(t *testing.T) {
+	// A file where the log directory should be makes every open fail
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "logs")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	logFile := filepath.Join(blocker, "app.log")
+
+	var mu sync.Mutex
+	var reports []string
+	handler := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, err.Error())
+	}
+	clock := &testClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l, _ := newTestLogger(t, SetMode(Synchronous), SetClock(clock.Now),
+		SetErrorHandler(handler), SetDefaultFileName(logFile))
+
+	for i := 0; i < DefaultDegradeThreshold+2; i++ {
+		l.Info("lost")
+	}
+
+	mu.Lock()
+	got := append([]string(nil), reports...)
+	mu.Unlock()
+	if len(got) != DefaultDegradeThreshold {
+		t.Fatalf("got %d reports, want %d: %q", len(got), DefaultDegradeThreshold, got)
+	}
+	if !strings.Contains(got[len(got)-1], "degraded") {
+		t.Errorf("last report = %q, want the degraded warning", got[len(got)-1])
+	}
+	stats := l.Stats()
+	if len(stats.DegradedFiles) != 1 || !strings.HasSuffix(stats.DegradedFiles[0], "app.log") {
+		t.Errorf("DegradedFiles = %q, want the log file", stats.DegradedFiles)
+	}
+	if stats.DegradedDrops != 2 {
+		t.Errorf("DegradedDrops = %d, want 2", stats.DegradedDrops)
+	}
+
+	// A failed probe after the cooldown extends the degraded state silently
+	clock.Add(DefaultDegradeCooldown)
+	l.Info("probe")
+	l.Info("skipped")
+	mu.Lock()
+	if len(reports) != DefaultDegradeThreshold {
+		t.Errorf("failed probe reported: %q", reports[DefaultDegradeThreshold:])
+	}
+	mu.Unlock()
+	if drops := l.Stats().DegradedDrops; drops != 3 {
+		t.Errorf("DegradedDrops = %d, want 3", drops)
+	}
+
+	// Once the directory is writable again, the next probe recovers the file
+	if err := os.Remove(blocker); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	clock.Add(DefaultDegradeCooldown)
+	l.Info("recovered")
+	l.Info("written")
+
+	if files := l.Stats().DegradedFiles; len(files) != 0 {
+		t.Errorf("DegradedFiles = %q after recovery, want none", files)
+	}
+	mu.Lock()
+	if last := reports[len(reports)-1]; !strings.Contains(last, "recovered") {
+		t.Errorf("last report = %q, want the recovery notice", last)
+	}
+	mu.Unlock()
+	content := readFile(t, logFile)
+	if !strings.Contains(content, "INFO: recovered") || !strings.Contains(content, "INFO: written") {
+		t.Errorf("log file = %q, want the messages after recovery", content)
+	}
+}
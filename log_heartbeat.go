@@ -0,0 +1,46 @@
+package asynclog
+
+import (
+	"fmt"
+	"time"
+)
+
+// HeartbeatEvent is the event name of heartbeat messages.
+const HeartbeatEvent = "heartbeat"
+
+// SetHeartbeat makes the logger log an "alive" message at the Info level every
+// interval, with its uptime and queue stats as params. Heartbeats go through
+// the normal pipeline, so a process that died silently shows up as a gap in
+// its log files. The heartbeat stops when the logger is closed.
+func SetHeartbeat(interval time.Duration) LoggerOption {
+	return func(l *Logger) error {
+		if interval <= 0 {
+			return fmt.Errorf("heartbeat interval must be positive")
+		}
+		l.heartbeat = interval
+		return nil
+	}
+}
+
+// heartbeatLoop logs a heartbeat every interval until the logger is closed.
+func (l *Logger) heartbeatLoop() {
+	ticker := time.NewTicker(l.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			params := map[string]interface{}{
+				"uptime":       l.now().Sub(l.started).Round(time.Second).String(),
+				"queued":       l.pending.Load(),
+				"write_errors": l.writeErrors.Load(),
+			}
+			if l.loggerName != "" {
+				params["logger"] = l.loggerName
+			}
+			l.log(LogLevelInfo, "alive", SetEventName(HeartbeatEvent), SetLogParams(params))
+		case <-l.stop:
+			return
+		}
+	}
+}
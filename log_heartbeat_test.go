@@ -0,0 +1,67 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetHeartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(tt.mode), SetHeartbeat(10*time.Millisecond),
+				SetLoggerName("worker"))
+			received := make(chan LogMessage, 16)
+			l.OnLevel(LogLevelInfo, func(m LogMessage) {
+				select {
+				case received <- m:
+				default:
+				}
+			})
+
+			var m LogMessage
+			select {
+			case m = <-received:
+			case <-time.After(time.Second):
+				t.Fatal("no heartbeat was logged")
+			}
+			if m.Message != "alive" || m.Event != HeartbeatEvent || m.Level != LogLevelInfo {
+				t.Errorf("heartbeat = %v %q %q, want Info alive %q", m.Level, m.Message, m.Event, HeartbeatEvent)
+			}
+			for _, key := range []string{"uptime", "queued", "write_errors"} {
+				if _, ok := m.Params[key]; !ok {
+					t.Errorf("heartbeat params = %v, want %q", m.Params, key)
+				}
+			}
+			if m.Params["logger"] != "worker" {
+				t.Errorf("logger param = %v, want worker", m.Params["logger"])
+			}
+
+			// No heartbeats are written after the logger is closed
+			l.Close()
+			written := readFile(t, file)
+			if !strings.Contains(written, "INFO: alive") {
+				t.Errorf("file = %q, want the heartbeat", written)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if got := readFile(t, file); got != written {
+				t.Errorf("file grew after Close: %q", strings.TrimPrefix(got, written))
+			}
+		})
+	}
+}
+
+func TestSetHeartbeatRejectsNonPositive(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := NewLogger(SetHeartbeat(interval)); err == nil {
+			t.Errorf("NewLogger accepted a heartbeat interval of %v", interval)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package asynclog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructuredJSON(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 500, time.UTC)
+	tests := []struct {
+		name    string
+		opts    []LoggerOption
+		message string
+		params  map[string]interface{}
+		want    map[string]interface{}
+	}{
+		{"core fields", nil, "started", nil, map[string]interface{}{
+			"time": "2024-06-01T12:00:00.0000005Z", "level": "INFO", "msg": "started",
+		}},
+		{"params merged", nil, "request", map[string]interface{}{"method": "GET", "status": 200}, map[string]interface{}{
+			"msg": "request", "method": "GET", "status": 200.0,
+		}},
+		{"param named like a core field", nil, "request", map[string]interface{}{"msg": "shadow"}, map[string]interface{}{
+			"msg": "request", "param.msg": "shadow",
+		}},
+		{"multi-line message", nil, "line1\nline2", nil, map[string]interface{}{"msg": "line1\nline2"}},
+		{"format version", []LoggerOption{SetEmitFormatVersion(true)}, "started", nil, map[string]interface{}{
+			"v": float64(FormatVersion),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			opts := append([]LoggerOption{SetMode(Synchronous), SetStructuredJSON(true), SetUTC(true),
+				SetClock(func() time.Time { return at }), EnableSourceInfo(true),
+				EnableConsoleOutput(true), SetConsoleWriter(&console)}, tt.opts...)
+			l, file := newTestLogger(t, opts...)
+
+			l.Info(tt.message, SetLogParams(tt.params))
+
+			lines := strings.Split(strings.TrimSuffix(readFile(t, file), "\n"), "\n")
+			if len(lines) != 1 {
+				t.Fatalf("file has %d lines, want one JSON object: %q", len(lines), lines)
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+				t.Fatalf("line %q is not valid JSON: %v", lines[0], err)
+			}
+			if source, _ := got["source"].(string); !strings.HasPrefix(source, "log_json_test.go:") {
+				t.Errorf("source = %v, want the test file", got["source"])
+			}
+			for key, want := range tt.want {
+				if got[key] != want {
+					t.Errorf("%s = %#v, want %#v", key, got[key], want)
+				}
+			}
+
+			// The console stays human-readable
+			if strings.HasPrefix(console.String(), "{") || !strings.Contains(console.String(), "INFO: ") {
+				t.Errorf("console = %q, want a text line", console.String())
+			}
+		})
+	}
+}
+
+func TestJSONLineHMAC(t *testing.T) {
+	key := []byte("secret")
+	tests := []struct {
+		name string
+		opts []LoggerOption
+	}{
+		{"structured json", []LoggerOption{SetStructuredJSON(true)}},
+		{"json file formatter", []LoggerOption{SetFileFormatter(FormatLineAsJSON)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, append([]LoggerOption{SetMode(Synchronous), SetLineHMAC(key)}, tt.opts...)...)
+
+			l.Info("started", SetLogParams(map[string]interface{}{"port": 8080}))
+
+			line := strings.TrimSuffix(readFile(t, file), "\n")
+			var fields map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &fields); err != nil {
+				t.Fatalf("line %q is not valid JSON: %v", line, err)
+			}
+			if mac, _ := fields["hmac"].(string); len(mac) != 64 {
+				t.Errorf("hmac field = %v, want a hex HMAC-SHA256", fields["hmac"])
+			}
+			if fields["msg"] != "started" || fields["port"] != float64(8080) {
+				t.Errorf("fields = %v, want the message and its params", fields)
+			}
+			if _, ok := VerifyLineHMAC(line, key); !ok {
+				t.Errorf("VerifyLineHMAC(%q) failed", line)
+			}
+		})
+	}
+}
+
+func TestFormatLineAsJSONFieldOrder(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		msg  LogMessage
+		want string
+	}{
+		{"core fields", LogMessage{Level: LogLevelInfo, Time: at, Message: "started"},
+			`{"time":"2024-06-01T12:00:00Z","level":"INFO","msg":"started"}`},
+		{"optional fields and sorted params", LogMessage{Level: LogLevelError, Time: at, Message: "failed",
+			Component: "db", Event: "db.error", Source: "main.go:42",
+			Params: map[string]interface{}{"zone": "eu", "attempt": 3}},
+			`{"time":"2024-06-01T12:00:00Z","level":"ERROR","msg":"failed","component":"db","event":"db.error",` +
+				`"source":"main.go:42","attempt":3,"zone":"eu"}`},
+		{"params named like core fields", LogMessage{Level: LogLevelInfo, Time: at, Message: "started",
+			Params: map[string]interface{}{"msg": "shadow", "level": 1}},
+			`{"time":"2024-06-01T12:00:00Z","level":"INFO","msg":"started","param.level":1,"param.msg":"shadow"}`},
+		{"durations in milliseconds", LogMessage{Level: LogLevelInfo, Time: at, Message: "done",
+			Params: map[string]interface{}{"took": 1500 * time.Millisecond}},
+			`{"time":"2024-06-01T12:00:00Z","level":"INFO","msg":"done","took":1500}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The order must not depend on map iteration
+			for i := 0; i < 10; i++ {
+				if got := FormatLineAsJSON(tt.msg); got != tt.want {
+					t.Fatalf("got  %s\nwant %s", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package asynclog
+
+import "strconv"
+
+// SetLineNumbers prepends a per-file record number to each record written to
+// a text log file, e.g. "4213 [2024/06/01 12:00:00] INFO: ...", so a line can be
+// referred to in a support ticket. Numbers start at 1 for each file in every run
+// of the program and keep counting when an idle file handle is closed and the
+// file is reopened. Records spanning several lines are numbered once.
+// The number is not covered by SetLineHMAC.
+func SetLineNumbers(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.lineNumbers = enable
+		return nil
+	}
+}
+
+// numberRecords prepends the next record numbers of the file to the records.
+// The caller must hold fileMutex.
+func (l *Logger) numberRecords(filename string, records []string) []string {
+	numbered := make([]string, len(records))
+	for i, record := range records {
+		l.lineCounts[filename]++
+		numbered[i] = strconv.FormatUint(l.lineCounts[filename], 10) + " " + record
+	}
+	return numbered
+}
@@ -0,0 +1,18 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package asynclog
+
+import "os"
+
+// fileLockingSupported reports whether advisory file locks are available.
+const fileLockingSupported = false
+
+// lockFile is a no-op on platforms without flock.
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock.
+func unlockFile(file *os.File) error {
+	return nil
+}
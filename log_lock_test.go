@@ -0,0 +1,55 @@
+package asynclog
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetFileLocking(t *testing.T) {
+	if !fileLockingSupported {
+		if _, err := NewLogger(SetFileLocking(true)); err == nil {
+			t.Error("NewLogger accepted file locking on a platform without flock")
+		}
+		t.Skip("file locking is not supported on this platform")
+	}
+	l, file := newTestLogger(t, SetMode(Synchronous), SetFileLocking(true))
+
+	// Another writer holding the lock keeps the logger from writing
+	other, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	defer other.Close()
+	if err := lockFile(other); err != nil {
+		t.Fatalf("lockFile: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		l.Info("waited")
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("logger wrote while another writer held the lock")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if _, err := other.WriteString("other writer\n"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	if err := unlockFile(other); err != nil {
+		t.Fatalf("unlockFile: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logger did not write after the lock was released")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(readFile(t, file), "\n"), "\n")
+	if len(lines) != 2 || lines[0] != "other writer" || !strings.HasSuffix(lines[1], "INFO: waited") {
+		t.Errorf("lines = %q, want the other writer's line, then the logged one", lines)
+	}
+}
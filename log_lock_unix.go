@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package asynclog
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileLockingSupported reports whether advisory file locks are available.
+const fileLockingSupported = true
+
+// lockFile acquires an exclusive advisory lock (flock) on the file,
+// waiting for other processes holding it to release it.
+func lockFile(file *os.File) error {
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockFile releases the advisory lock on the file.
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,172 @@
+package asynclog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestSuffix is appended to a log file name to name its manifest of rotated files.
+const ManifestSuffix = ".manifest.json"
+
+// ManifestEntry describes a rotated generation of a log file in its manifest.
+type ManifestEntry struct {
+	File  string     `json:"file"`            // Name of the rotated file, without its directory.
+	First *time.Time `json:"first,omitempty"` // Time of the first write, unless the file predates the logger.
+	Last  time.Time  `json:"last"`            // Time of the last write.
+	Size  int64      `json:"size"`            // Size of the file in bytes.
+}
+
+// writeSpan records when a log file was written first and last.
+type writeSpan struct {
+	first   time.Time // Time of the first write, zero until written.
+	last    time.Time // Time of the last write.
+	partial bool      // Whether the file had content before it was first opened.
+}
+
+// SetWriteManifest maintains a JSON manifest next to every rotated log file,
+// e.g. "app.log.manifest.json", listing its rotated generations from newest
+// to oldest with the times of their first and last writes and their sizes,
+// so tools can find the file covering a time range without scanning them all.
+// The manifest is rewritten on rotation only and follows the pruning of
+// SetMaxBackups. Write times are taken from the logger's clock.
+func SetWriteManifest(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.writeManifest = enable
+		return nil
+	}
+}
+
+// ReadManifest returns the manifest entries of the logical log file baseName,
+// ordered from newest to oldest.
+func ReadManifest(baseName string) ([]ManifestEntry, error) {
+	data, err := os.ReadFile(baseName + ManifestSuffix)
+	if err != nil {
+		return nil, err
+	}
+	var entries []ManifestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// trackOpenLocked starts tracking the write times of a file being opened,
+// unless they are already tracked. The caller must hold fileMutex.
+func (l *Logger) trackOpenLocked(filename string, file *os.File) {
+	if _, ok := l.writeSpans[filename]; ok {
+		return
+	}
+	var span writeSpan
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		span.partial = true
+	}
+	l.writeSpans[filename] = span
+}
+
+// trackWriteLocked records a write to a file. The caller must hold fileMutex.
+func (l *Logger) trackWriteLocked(filename string) {
+	span := l.writeSpans[filename]
+	now := l.now()
+	if span.first.IsZero() {
+		span.first = now
+	}
+	span.last = now
+	l.writeSpans[filename] = span
+}
+
+// updateManifestLocked rewrites the manifest of a log file after it has been
+// moved to its first rotated generation. renames maps the previous names of
+// shifted generations to their new names, or to "" if they were deleted.
+// The caller must hold fileMutex.
+func (l *Logger) updateManifestLocked(baseName string, renames map[string]string) error {
+	// Carry the entries of generations still on disk over to their new names
+	known := make(map[string]ManifestEntry)
+	previous, err := ReadManifest(baseName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		l.diagf("Failed to read log manifest, rebuilding it: %w", err)
+	}
+	dir := filepath.Dir(baseName)
+	for _, entry := range previous {
+		name := filepath.Join(dir, entry.File)
+		if renamed, ok := renames[name]; ok {
+			if renamed == "" {
+				continue
+			}
+			name = renamed
+		}
+		entry.File = filepath.Base(name)
+		known[entry.File] = entry
+	}
+
+	span := l.writeSpans[baseName]
+	delete(l.writeSpans, baseName)
+	entry := ManifestEntry{File: filepath.Base(backupFileName(baseName, 1)), Last: span.last}
+	if !span.first.IsZero() && !span.partial {
+		first := span.first
+		entry.First = &first
+	}
+	known[entry.File] = entry
+
+	files, err := RotatedFiles(baseName)
+	if err != nil {
+		return err
+	}
+	entries := make([]ManifestEntry, 0, len(files))
+	for _, name := range files {
+		info, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		entry, ok := known[filepath.Base(name)]
+		if !ok || entry.Last.IsZero() {
+			entry.File, entry.Last = filepath.Base(name), info.ModTime()
+		}
+		entry.Size = info.Size()
+		entries = append(entries, entry)
+	}
+
+	return writeManifestFile(baseName, entries)
+}
+
+// pruneManifestLocked removes the entries of deleted files from the manifest
+// of a log file, and the manifest itself once no entry is left. removed holds
+// the deleted file names, without their directory. The caller must hold fileMutex.
+func (l *Logger) pruneManifestLocked(baseName string, removed map[string]bool) error {
+	previous, err := ReadManifest(baseName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	entries := make([]ManifestEntry, 0, len(previous))
+	for _, entry := range previous {
+		if !removed[entry.File] {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == len(previous) {
+		return nil
+	}
+	if len(entries) == 0 {
+		return os.Remove(baseName + ManifestSuffix)
+	}
+	return writeManifestFile(baseName, entries)
+}
+
+// writeManifestFile replaces the manifest of a log file with the entries.
+func writeManifestFile(baseName string, entries []ManifestEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Replace the manifest atomically, so readers never see a partial one
+	tmp := baseName + ManifestSuffix + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, baseName+ManifestSuffix)
+}
@@ -0,0 +1,138 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteManifest(t *testing.T) {
+	tests := []struct {
+		name       string
+		rotations  int
+		maxBackups int
+		want       []string
+	}{
+		{"single rotation", 1, 0, []string{"app.log.1"}},
+		{"generations shift", 3, 0, []string{"app.log.1", "app.log.2", "app.log.3"}},
+		{"pruned generations are dropped", 4, 2, []string{"app.log.1", "app.log.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			now := start
+			record := strings.Repeat("x", 40)
+			opts := []LoggerOption{SetMode(Synchronous), SetClock(func() time.Time { return now }),
+				SetMaxFileSize(int64(2 * (len(record) + 40))), SetWriteManifest(true)}
+			if tt.maxBackups > 0 {
+				opts = append(opts, SetMaxBackups(tt.maxBackups))
+			}
+			l, file := newTestLogger(t, opts...)
+
+			// Every pair of records fills a file; the next write rotates it
+			for i := 0; i < 2*tt.rotations+1; i++ {
+				l.Info(record)
+				now = now.Add(time.Minute)
+			}
+
+			entries, err := ReadManifest(file)
+			if err != nil {
+				t.Fatalf("ReadManifest: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("manifest has %d entries, want %d: %+v", len(entries), len(tt.want), entries)
+			}
+			for i, entry := range entries {
+				if entry.File != tt.want[i] {
+					t.Errorf("entry %d is %q, want %q", i, entry.File, tt.want[i])
+				}
+				info, err := os.Stat(filepath.Join(filepath.Dir(file), entry.File))
+				if err != nil {
+					t.Fatalf("Stat: %v", err)
+				}
+				if entry.Size != info.Size() {
+					t.Errorf("entry %q size = %d, want %d", entry.File, entry.Size, info.Size())
+				}
+				// Generation n (1-based) holds the records n pairs before the current file
+				rotation := tt.rotations - 1 - i
+				wantFirst := start.Add(time.Duration(2*rotation) * time.Minute)
+				if entry.First == nil || !entry.First.Equal(wantFirst) {
+					t.Errorf("entry %q first = %v, want %v", entry.File, entry.First, wantFirst)
+				}
+				if want := wantFirst.Add(time.Minute); !entry.Last.Equal(want) {
+					t.Errorf("entry %q last = %v, want %v", entry.File, entry.Last, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteManifestDisabled(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetMaxFileSize(10))
+
+	l.Info("first record")
+	l.Info("second record")
+
+	if _, err := os.Stat(file + ManifestSuffix); !os.IsNotExist(err) {
+		t.Errorf("manifest written without SetWriteManifest: %v", err)
+	}
+}
+
+func TestManifestFollowsDailyPruning(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	record := strings.Repeat("x", 40)
+	l, file := newTestLogger(t, SetMode(Synchronous), SetClock(clock.Now), SetDailyRotation(true),
+		SetMaxFileSize(int64(2*(len(record)+40))), SetMaxBackups(1), SetWriteManifest(true))
+
+	// Every day rotates once by size; each new day prunes all but the previous one
+	for day := 0; day < 3; day++ {
+		for i := 0; i < 3; i++ {
+			l.Info(record)
+		}
+		clock.Add(24 * time.Hour)
+	}
+	l.Info(record)
+
+	dir := filepath.Dir(file)
+	for _, day := range []string{"2024-06-01", "2024-06-02"} {
+		manifest := filepath.Join(dir, "app-"+day+".log"+ManifestSuffix)
+		if _, err := os.Stat(manifest); !os.IsNotExist(err) {
+			t.Errorf("Stat(%s) error = %v, want the manifest of the pruned day deleted", manifest, err)
+		}
+	}
+	entries, err := ReadManifest(filepath.Join(dir, "app-2024-06-03.log"))
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	if len(entries) != 1 || entries[0].File != "app-2024-06-03.log.1" {
+		t.Errorf("manifest of the kept day = %+v, want its rotated file", entries)
+	}
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(dir, entry.File)); err != nil {
+			t.Errorf("manifest lists %q: %v", entry.File, err)
+		}
+	}
+}
+
+func TestPruneManifestKeepsOtherEntries(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "app.log")
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []ManifestEntry{{File: "app.log.1", Last: at}, {File: "app.log.2", Last: at}}
+	if err := writeManifestFile(base, entries); err != nil {
+		t.Fatalf("writeManifestFile: %v", err)
+	}
+
+	l := &Logger{}
+	if err := l.pruneManifestLocked(base, map[string]bool{"app.log.2": true}); err != nil {
+		t.Fatalf("pruneManifestLocked: %v", err)
+	}
+	got, err := ReadManifest(base)
+	if err != nil {
+		t.Fatalf("ReadManifest: %v", err)
+	}
+	if len(got) != 1 || got[0].File != "app.log.1" {
+		t.Errorf("manifest = %+v, want only app.log.1", got)
+	}
+}
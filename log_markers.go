@@ -0,0 +1,35 @@
+package asynclog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SetRecordMarkers frames text file records for parsers that cannot tell where
+// a multi-line record, such as one with a stack trace, ends. Each record starts
+// with the start marker and every further line of it with the continuation
+// prefix, e.g. SetRecordMarkers("", "\t") indents continuation lines the way
+// log4j-style parsers expect. Console output and sinks are not framed.
+func SetRecordMarkers(start, continuation string) LoggerOption {
+	return func(l *Logger) error {
+		if start == "" && continuation == "" {
+			return fmt.Errorf("record markers must not both be empty")
+		}
+		if strings.Contains(start, "\n") || strings.Contains(continuation, "\n") {
+			return fmt.Errorf("record markers must not contain newlines")
+		}
+		l.recordStart = start
+		l.recordCont = continuation
+		l.markRecords = true
+		return nil
+	}
+}
+
+// frameRecords frames the records with the start marker and continuation prefix.
+func (l *Logger) frameRecords(records []string) []string {
+	framed := make([]string, len(records))
+	for i, record := range records {
+		framed[i] = l.recordStart + strings.ReplaceAll(record, "\n", "\n"+l.recordCont)
+	}
+	return framed
+}
@@ -0,0 +1,57 @@
+package asynclog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSetRecordMarkers(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	stamp := "[2024/06/01 12:00:00] "
+	tests := []struct {
+		name        string
+		start, cont string
+		wantFile    string
+	}{
+		{"sentinel and prefix", ">> ", ".. ",
+			">> " + stamp + "ERROR: failed\n.. retry later\n..   \"code\": 7\n>> " + stamp + "INFO: done\n"},
+		{"indented continuation", "", "\t",
+			stamp + "ERROR: failed\n\tretry later\n\t  \"code\": 7\n" + stamp + "INFO: done\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(func() time.Time { return at }),
+				EnableConsoleOutput(true), SetConsoleWriter(&console), SetColorEnabled(false),
+				SetRecordMarkers(tt.start, tt.cont))
+
+			l.Error("failed\nretry later", SetLogParams(map[string]interface{}{"code": 7}))
+			l.Info("done")
+
+			if got := readFile(t, file); got != tt.wantFile {
+				t.Errorf("file = %q, want %q", got, tt.wantFile)
+			}
+			// Console output is not framed
+			wantConsole := stamp + "ERROR: failed\nretry later\n  \"code\": 7\n" + stamp + "INFO: done\n"
+			if got := console.String(); got != wantConsole {
+				t.Errorf("console = %q, want %q", got, wantConsole)
+			}
+		})
+	}
+}
+
+func TestSetRecordMarkersRejectsInvalidMarkers(t *testing.T) {
+	tests := []struct {
+		start, cont string
+	}{
+		{"", ""},
+		{">>\n", ""},
+		{"", "\n\t"},
+	}
+	for _, tt := range tests {
+		if _, err := NewLogger(SetRecordMarkers(tt.start, tt.cont)); err == nil {
+			t.Errorf("NewLogger accepted the record markers %q and %q", tt.start, tt.cont)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package asynclog
 
+import (
+	"fmt"
+	"time"
+)
+
 // LogMessage represents a log message with its level, content, and additional parameters.
+// Messages travel through the LogChannel with their structured fields only and
+// are formatted when processed, so param values must not be modified after
+// the log call. Callbacks receive both the structured fields and the output.
 type LogMessage struct {
 	Level          LogLevel               // Log level of the message (e.g., DEBUG, INFO, etc.)
 	Message        string                 // The actual log message
@@ -8,6 +16,80 @@ type LogMessage struct {
 	ConsoleMessage string                 // Formatted message for console output
 	File           string                 // The target log file
 	Params         map[string]interface{} // Additional parameters for the log message
+	Component      string                 // Name of the child logger that produced the message
+	Time           time.Time              // Time the message was logged
+	Source         string                 // Source file and line of the log call, if enabled
+	Event          string                 // Machine-queryable event name, e.g. "user.login"
+	collision      ParamCollision         // Policy for params set more than once
+	duplicateKey   string                 // First key set more than once under ParamReject
+	alsoStderr     bool                   // Whether the message is also printed to stderr regardless of level
+	owner          *Logger                // Logger reporting problems with the message, if any
+	pc             uintptr                // Program counter of the log call, if recorded by the caller
+}
+
+// displayParams returns the params to render, including the event name, if any.
+func (m LogMessage) displayParams() map[string]interface{} {
+	if m.Event == "" {
+		return m.Params
+	}
+	params := make(map[string]interface{}, len(m.Params)+1)
+	for key, value := range m.Params {
+		params[key] = value
+	}
+	params["event"] = m.Event
+	return params
+}
+
+// ParamCollision defines how a param key that is set more than once is resolved.
+type ParamCollision int
+
+const (
+	// ParamOverride lets the value set last win. Per-call params therefore
+	// override params bound earlier, such as those from a parent logger.
+	ParamOverride ParamCollision = iota
+
+	// ParamKeepBoth keeps every value by renaming later keys to "key#2", "key#3", etc.
+	ParamKeepBoth
+
+	// ParamReject drops a message setting a key more than once and reports
+	// the duplicate key as an error.
+	ParamReject
+)
+
+// setParam sets a single param, resolving key collisions according to the message policy.
+func (m *LogMessage) setParam(key string, value interface{}) {
+	if m.Params == nil {
+		m.Params = make(map[string]interface{})
+	}
+	if _, exists := m.Params[key]; !exists {
+		m.Params[key] = value
+		return
+	}
+
+	switch m.collision {
+	case ParamKeepBoth:
+		for i := 2; ; i++ {
+			renamed := fmt.Sprintf("%s#%d", key, i)
+			if _, exists := m.Params[renamed]; !exists {
+				m.Params[renamed] = value
+				return
+			}
+		}
+	case ParamReject:
+		if m.duplicateKey == "" {
+			m.duplicateKey = key
+		}
+	default:
+		m.Params[key] = value
+	}
+}
+
+// displayMessage returns the message text prefixed with its component name, if any.
+func (m LogMessage) displayMessage() string {
+	if m.Component == "" {
+		return m.Message
+	}
+	return "[" + m.Component + "] " + m.Message
 }
 
 // LogOption defines a function type for log message configuration.
@@ -23,8 +105,51 @@ func SetLogFile(file string) LogOption {
 
 // SetLogParams specifies additional parameters for a log message.
 // This function allows adding key-value pairs that provide additional information for the log message.
+// Params from repeated calls are merged; keys set more than once are resolved
+// by the logger's ParamCollision policy.
 func SetLogParams(params map[string]interface{}) LogOption {
 	return func(m *LogMessage) {
-		m.Params = params
+		for key, value := range params {
+			m.setParam(key, value)
+		}
+	}
+}
+
+// SetEventName sets a structured event name for a log message, e.g. "user.login".
+// Unlike the free-text message it is meant to be queried by machines,
+// and formatters render it as the "event" field.
+func SetEventName(name string) LogOption {
+	return func(m *LogMessage) {
+		m.Event = name
+	}
+}
+
+// SetTimestamp overrides the timestamp of a log message, e.g. when importing
+// historical events that should carry their original time instead of now.
+func SetTimestamp(t time.Time) LogOption {
+	return func(m *LogMessage) {
+		m.Time = t
+	}
+}
+
+// SetAlsoStderr makes a single message also print to stderr regardless of its
+// level and the console settings, e.g. to highlight an important Info line in
+// CI build output. It goes to the writer set with SetErrorConsoleWriter or,
+// without one, SetConsoleWriter, if any. Messages already printed to that
+// writer are not printed twice.
+func SetAlsoStderr() LogOption {
+	return func(m *LogMessage) {
+		m.alsoStderr = true
+	}
+}
+
+// CombineOptions binds several log options into a single reusable option.
+// Options are applied in the given order each time the result is used.
+func CombineOptions(opts ...LogOption) LogOption {
+	bound := append([]LogOption(nil), opts...)
+	return func(m *LogMessage) {
+		for _, opt := range bound {
+			opt(m)
+		}
 	}
 }
@@ -0,0 +1,93 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParamCollision(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   ParamCollision
+		want     []string
+		dropped  bool
+		wantDiag string
+	}{
+		{"override", ParamOverride, []string{`"user": call`}, false, ""},
+		{"keep both", ParamKeepBoth, []string{`"user": bound`, `"user#2": call`}, false, ""},
+		{"reject", ParamReject, nil, true, `duplicate param key "user"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags []string
+			l, file := newTestLogger(t, SetMode(Synchronous), SetParamCollision(tt.policy),
+				SetErrorHandler(func(err error) { diags = append(diags, err.Error()) }))
+
+			l.WithFields(map[string]interface{}{"user": "bound"}).
+				Info("login", SetLogParams(map[string]interface{}{"user": "call"}))
+
+			got := readFile(t, file)
+			if tt.dropped && got != "" {
+				t.Errorf("file = %q, want the message dropped", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file = %q, want it to contain %q", got, want)
+				}
+			}
+			if tt.wantDiag == "" && len(diags) > 0 {
+				t.Errorf("unexpected diagnostics: %v", diags)
+			}
+			if tt.wantDiag != "" && (len(diags) != 1 || !strings.Contains(diags[0], tt.wantDiag)) {
+				t.Errorf("diagnostics = %v, want one containing %q", diags, tt.wantDiag)
+			}
+		})
+	}
+}
+
+func TestParamRejectKeepsMessagesWithoutDuplicates(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetParamCollision(ParamReject))
+
+	l.WithFields(map[string]interface{}{"service": "api"}).
+		Info("login", SetLogParams(map[string]interface{}{"user": "alice"}))
+
+	got := readFile(t, file)
+	for _, want := range []string{"INFO: login", `"service": api`, `"user": alice`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("file = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestBoundOptions(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetFileNameSanitizer(acceptFileName))
+	jobs := filepath.Join(filepath.Dir(file), "jobs.log")
+	other := filepath.Join(filepath.Dir(file), "other.log")
+
+	opts := []LogOption{SetLogFile(jobs), SetLogParams(map[string]interface{}{"queue": "mail"})}
+	bound := l.Options(opts...)
+	// Changing the slice afterwards must not change the bound set
+	opts[0] = SetLogFile(other)
+
+	l.Info("started", bound)
+	l.Info("finished", bound)
+	// Options after the bound set are applied last and win
+	l.Info("moved", bound, SetLogFile(other))
+
+	got := readFile(t, jobs)
+	for _, want := range []string{"INFO: started", "INFO: finished", `"queue": mail`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("jobs file = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "moved") {
+		t.Errorf("jobs file = %q, want the overridden message elsewhere", got)
+	}
+	if got := readFile(t, other); !strings.Contains(got, "INFO: moved") || !strings.Contains(got, `"queue": mail`) {
+		t.Errorf("other file = %q, want the overridden message with the bound params", got)
+	}
+	if got := readFile(t, file); got != "" {
+		t.Errorf("default file = %q, want nothing", got)
+	}
+}
@@ -0,0 +1,108 @@
+package asynclog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// SetFileWriter sends file output to w instead of log files, e.g. a bytes.Buffer
+// in tests or a custom network writer. Records are written as they would be to
+// a file, and the per-message file names are ignored. Writes are serialized,
+// so w needs no locking of its own.
+func SetFileWriter(w io.Writer) LoggerOption {
+	return func(l *Logger) error {
+		if w == nil {
+			return fmt.Errorf("file writer must not be nil")
+		}
+		l.fileWriter = w
+		return nil
+	}
+}
+
+// SetConsoleWriter sends console output to w instead of stdout or stderr.
+func SetConsoleWriter(w io.Writer) LoggerOption {
+	return func(l *Logger) error {
+		if w == nil {
+			return fmt.Errorf("console writer must not be nil")
+		}
+		l.consoleWriter = w
+		return nil
+	}
+}
+
+// SetErrorToStderr prints console messages at the Warning level and above to
+// stderr, and the others to the console stream, usually stdout, so that
+// deployments can handle problems separately from normal output.
+func SetErrorToStderr(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		if enable {
+			l.errorConsole = os.Stderr
+		} else {
+			l.errorConsole = nil
+		}
+		return nil
+	}
+}
+
+// SetErrorConsoleWriter prints console messages at the Warning level and above
+// to w, like SetErrorToStderr does with stderr.
+func SetErrorConsoleWriter(w io.Writer) LoggerOption {
+	return func(l *Logger) error {
+		if w == nil {
+			return fmt.Errorf("error console writer must not be nil")
+		}
+		l.errorConsole = w
+		return nil
+	}
+}
+
+// writeFileWriter writes records meant for a file to the file writer.
+// The caller must hold fileMutex.
+func (l *Logger) writeFileWriter(filename string, messages []string) {
+	data := l.fileRecords(filename, messages)
+	if _, err := io.WriteString(l.fileWriter, data); err != nil {
+		l.recordWriteError(err)
+		l.diagf("Error writing to log writer: %w", err)
+		return
+	}
+	l.publishTail(filename, data)
+}
+
+// consoleOutput returns the writer receiving console output.
+func (l *Logger) consoleOutput() io.Writer {
+	if l.consoleWriter != nil {
+		return l.consoleWriter
+	}
+	return l.consoleFile()
+}
+
+// consoleOutputFor returns the writer receiving console output of the level.
+func (l *Logger) consoleOutputFor(level LogLevel) io.Writer {
+	if l.errorConsole != nil && level.AtLeast(LogLevelWarning) {
+		return l.errorConsole
+	}
+	return l.consoleOutput()
+}
+
+// stderrOutput returns the writer receiving messages escalated with
+// SetAlsoStderr: the error console writer, else the console writer, else stderr.
+func (l *Logger) stderrOutput() io.Writer {
+	if l.errorConsole != nil {
+		return l.errorConsole
+	}
+	if l.consoleWriter != nil {
+		return l.consoleWriter
+	}
+	return os.Stderr
+}
+
+// sameWriter reports whether two writers are the same, without panicking on
+// writers of types that cannot be compared.
+func sameWriter(a, b io.Writer) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -0,0 +1,242 @@
+package asynclog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutputWriters(t *testing.T) {
+	tests := []struct {
+		name       string
+		fileWriter bool
+		console    bool
+	}{
+		{"file writer", true, false},
+		{"console writer", false, true},
+		{"both writers", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fileBuf, consoleBuf bytes.Buffer
+			opts := []LoggerOption{SetMode(Synchronous), SetParamFormatter(FormatParamsAsLogfmt)}
+			if tt.fileWriter {
+				opts = append(opts, SetFileWriter(&fileBuf))
+			}
+			if tt.console {
+				opts = append(opts, EnableConsoleOutput(true), SetConsoleWriter(&consoleBuf))
+			}
+			l, file := newTestLogger(t, opts...)
+
+			l.Info("started", SetLogParams(map[string]interface{}{"port": 8080}))
+
+			want := "INFO: started\nport=8080\n"
+			if tt.fileWriter {
+				if got := fileBuf.String(); !strings.HasSuffix(got, want) {
+					t.Errorf("file writer received %q, want it to end in %q", got, want)
+				}
+				if _, err := os.Stat(file); !os.IsNotExist(err) {
+					t.Errorf("log file was created with a file writer set, Stat error = %v", err)
+				}
+			} else if got := readFile(t, file); !strings.HasSuffix(got, want) {
+				t.Errorf("file = %q, want it to end in %q", got, want)
+			}
+			if tt.console && !strings.Contains(consoleBuf.String(), want) {
+				t.Errorf("console writer received %q, want the message", consoleBuf.String())
+			}
+		})
+	}
+}
+
+func TestOutputWritersRejectNil(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  LoggerOption
+	}{
+		{"file writer", SetFileWriter(nil)},
+		{"console writer", SetConsoleWriter(nil)},
+		{"error console writer", SetErrorConsoleWriter(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLogger(tt.opt); err == nil {
+				t.Error("NewLogger accepted a nil writer")
+			}
+		})
+	}
+}
+
+func TestErrorConsoleSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    LogLevel
+		toStderr bool
+	}{
+		{"debug", LogLevelDebug, false},
+		{"info", LogLevelInfo, false},
+		{"warning", LogLevelWarning, true},
+		{"error", LogLevelError, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetConsoleLevel(LogLevelTrace), EnableConsoleOutput(true),
+				SetConsoleWriter(&stdout), SetErrorConsoleWriter(&stderr), SetColorEnabled(false))
+
+			l.log(tt.level, "message")
+
+			want, other := &stdout, &stderr
+			if tt.toStderr {
+				want, other = &stderr, &stdout
+			}
+			if !strings.Contains(want.String(), ": message\n") {
+				t.Errorf("expected stream = %q, want the message", want.String())
+			}
+			if other.Len() != 0 {
+				t.Errorf("other stream = %q, want nothing", other.String())
+			}
+		})
+	}
+}
+
+func TestSetErrorToStderr(t *testing.T) {
+	var stdout bytes.Buffer
+	stderr := captureStderr(t, func() {
+		l, err := NewLogger(SetDefaultFileName(filepath.Join(t.TempDir(), "app.log")), SetMode(Synchronous),
+			SetConsoleWriter(&stdout), SetErrorToStderr(true), SetColorEnabled(false))
+		if err != nil {
+			t.Fatalf("NewLogger: %v", err)
+		}
+		l.Info("started")
+		l.Error("failed")
+		l.Close()
+	})
+
+	if !strings.Contains(stdout.String(), "INFO: started") || strings.Contains(stdout.String(), "failed") {
+		t.Errorf("stdout = %q, want only the Info message", stdout.String())
+	}
+	if !strings.Contains(stderr, "ERROR: failed") || strings.Contains(stderr, "started") {
+		t.Errorf("stderr = %q, want only the Error message", stderr)
+	}
+}
+
+func TestAlsoStderrUsesConfiguredWriters(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []LoggerOption
+		errorConsole bool
+		wantConsole  int
+		wantErrors   int
+	}{
+		{"below console level", []LoggerOption{SetConsoleLevel(LogLevelError)}, false, 1, 0},
+		{"already printed", nil, false, 1, 0},
+		{"console output disabled", []LoggerOption{EnableConsoleOutput(false)}, false, 1, 0},
+		{"error console", nil, true, 1, 1},
+		{"error console, below console level", []LoggerOption{SetConsoleLevel(LogLevelError)}, true, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console, errs bytes.Buffer
+			opts := []LoggerOption{SetMode(Synchronous), EnableConsoleOutput(true), SetConsoleWriter(&console)}
+			if tt.errorConsole {
+				opts = append(opts, SetErrorConsoleWriter(&errs))
+			}
+			l, _ := newTestLogger(t, append(opts, tt.opts...)...)
+
+			stderr := captureStderr(t, func() {
+				l.Info("deployed", SetAlsoStderr())
+			})
+
+			if got := strings.Count(console.String(), "deployed"); got != tt.wantConsole {
+				t.Errorf("console received the message %d times, want %d: %q", got, tt.wantConsole, console.String())
+			}
+			if got := strings.Count(errs.String(), "deployed"); got != tt.wantErrors {
+				t.Errorf("error console received the message %d times, want %d: %q", got, tt.wantErrors, errs.String())
+			}
+			if stderr != "" {
+				t.Errorf("stderr = %q, want nothing with configured writers", stderr)
+			}
+		})
+	}
+}
+
+func TestAlsoStderrDefaultsToStderr(t *testing.T) {
+	l, _ := newTestLogger(t, SetMode(Synchronous))
+
+	stderr := captureStderr(t, func() {
+		l.Info("deployed", SetAlsoStderr())
+	})
+
+	if !strings.Contains(stderr, "INFO: deployed") {
+		t.Errorf("stderr = %q, want the message", stderr)
+	}
+}
+
+// captureStreams returns what fn writes to stdout and stderr.
+func captureStreams(t *testing.T, fn func()) (string, string) {
+	t.Helper()
+	var stdout string
+	stderr := captureStderr(t, func() {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("Pipe: %v", err)
+		}
+		saved := os.Stdout
+		os.Stdout = w
+		defer func() { os.Stdout = saved }()
+
+		fn()
+		w.Close()
+		var buf bytes.Buffer
+		buf.ReadFrom(r)
+		stdout = buf.String()
+	})
+	return stdout, stderr
+}
+
+func TestSetConsoleStream(t *testing.T) {
+	tests := []struct {
+		name       string
+		stream     ConsoleStream
+		wantStdout bool
+	}{
+		{"stdout", Stdout, true},
+		{"stderr", Stderr, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetMode(Synchronous), EnableConsoleOutput(true), SetConsoleStream(tt.stream))
+
+			stdout, stderr := captureStreams(t, func() {
+				l.Info("started")
+				l.Error("failed")
+			})
+
+			got, other := stdout, stderr
+			if !tt.wantStdout {
+				got, other = stderr, stdout
+			}
+			if !strings.Contains(got, "started") || !strings.Contains(got, "failed") || other != "" {
+				t.Errorf("stdout = %q, stderr = %q, want every message on %s", stdout, stderr, tt.name)
+			}
+		})
+	}
+}
+
+func TestSetConsoleStreamWithWriter(t *testing.T) {
+	var console bytes.Buffer
+	l, _ := newTestLogger(t, SetMode(Synchronous), EnableConsoleOutput(true), SetConsoleStream(Stderr),
+		SetConsoleWriter(&console))
+
+	stdout, stderr := captureStreams(t, func() { l.Info("started") })
+
+	if !strings.Contains(console.String(), "started") || stdout != "" || stderr != "" {
+		t.Errorf("console = %q, stdout = %q, stderr = %q, want the message on the console writer only",
+			console.String(), stdout, stderr)
+	}
+	if _, err := NewLogger(SetConsoleStream(ConsoleStream(5))); err == nil {
+		t.Error("NewLogger accepted an unknown stream")
+	}
+}
@@ -0,0 +1,48 @@
+package asynclog
+
+import "fmt"
+
+// OverflowPolicy defines what happens to a message when the LogChannel is full.
+type OverflowPolicy int
+
+const (
+	// Block waits until the channel has room, so no message is lost.
+	Block OverflowPolicy = iota
+
+	// DropNewest discards the message being logged, so a burst of messages
+	// cannot stall the application. Dropped messages are counted by DroppedCount.
+	DropNewest
+)
+
+// SetOverflowPolicy sets what happens to messages logged while the LogChannel is full.
+// It has no effect in synchronous mode.
+func SetOverflowPolicy(policy OverflowPolicy) LoggerOption {
+	return func(l *Logger) error {
+		if policy != Block && policy != DropNewest {
+			return fmt.Errorf("unknown overflow policy: %d", policy)
+		}
+		l.overflow = policy
+		return nil
+	}
+}
+
+// enqueue sends a message to the LogChannel according to the overflow policy.
+func (l *Logger) enqueue(logMsg LogMessage) {
+	l.pending.Add(1)
+	if l.overflow == Block {
+		l.LogChannel <- logMsg
+		return
+	}
+	select {
+	case l.LogChannel <- logMsg:
+	default:
+		l.pending.Add(-1)
+		l.dropped.Add(1)
+	}
+}
+
+// DroppedCount returns the number of messages discarded because the
+// LogChannel was full, see SetOverflowPolicy.
+func (l *Logger) DroppedCount() uint64 {
+	return l.root().dropped.Load()
+}
@@ -0,0 +1,65 @@
+package asynclog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		extra       int
+		wantBlock   bool
+		wantDropped uint64
+	}{
+		{"drop newest", DropNewest, 5, false, 5},
+		{"block", Block, 1, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetBufferSize(1), SetOverflowPolicy(tt.policy))
+			started, release := make(chan struct{}), make(chan struct{})
+			l.AddHook(func(m LogMessage) {
+				if m.Message == "busy" {
+					close(started)
+					<-release
+				}
+			})
+
+			// Keep the processor busy and fill the buffer
+			l.Info("busy")
+			<-started
+			l.Info("queued")
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 0; i < tt.extra; i++ {
+					l.Info("overflow")
+				}
+			}()
+			select {
+			case <-done:
+				if tt.wantBlock {
+					t.Error("logging to a full buffer did not block")
+				}
+			case <-time.After(100 * time.Millisecond):
+				if !tt.wantBlock {
+					t.Error("logging to a full buffer blocked")
+				}
+			}
+			if got := l.DroppedCount(); got != tt.wantDropped {
+				t.Errorf("DroppedCount = %d, want %d", got, tt.wantDropped)
+			}
+			close(release)
+			<-done
+		})
+	}
+}
+
+func TestSetOverflowPolicyRejectsUnknownPolicy(t *testing.T) {
+	if _, err := NewLogger(SetOverflowPolicy(OverflowPolicy(42))); err == nil {
+		t.Error("NewLogger accepted an unknown overflow policy")
+	}
+}
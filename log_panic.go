@@ -0,0 +1,78 @@
+package asynclog
+
+import (
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// panicDrainTimeout bounds how long a captured panic waits for queued messages.
+const panicDrainTimeout = time.Second
+
+// CapturePanicToFile records an unrecovered panic in the default log file and
+// re-panics. Go offers no hook for the final, unrecovered panic of a process,
+// so this only works when deferred at the top of main (or of a goroutine):
+//
+//	func main() {
+//		logger, _ := asynclog.NewLogger()
+//		defer logger.CapturePanicToFile()
+//		...
+//	}
+//
+// Panics in other goroutines without their own deferred call are not captured.
+func (l *Logger) CapturePanicToFile() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	l.logPanic(r, debug.Stack(), CaptureStack(0))
+	panic(r)
+}
+
+// LogPanic synchronously writes a panic value and its stack trace to the default
+// log file at the Fatal level, after giving queued messages a short time to be
+// written. The file is flushed to disk before LogPanic returns. Nothing is
+// written once the logger is closed.
+func (l *Logger) LogPanic(value interface{}, stack []byte) {
+	l.logPanic(value, stack, nil)
+}
+
+// logPanic implements LogPanic. Structured formats record the frames,
+// if given, or else the text stack trace.
+func (l *Logger) logPanic(value interface{}, stack []byte, frames Stack) {
+	root := l.root()
+	root.WaitIdle(panicDrainTimeout)
+
+	// Write under the send lock, so Close cannot close the file meanwhile
+	root.sendMutex.RLock()
+	defer root.sendMutex.RUnlock()
+	if root.closed {
+		root.diagf("Dropped panic %q: logger is closed", fmt.Sprint(value))
+		return
+	}
+
+	logMsg := LogMessage{
+		Level:     LogLevelFatal,
+		Message:   fmt.Sprintf("panic: %v", value),
+		File:      root.defaultFile(),
+		Component: l.name,
+		Time:      root.now().In(root.timeLocation()),
+	}
+	// Keep the stack readable in text lines, and as a param in structured formats
+	plainText := root.fileFormat == FormatText && root.fileFormatter == nil && !root.structuredJSON
+	if !plainText && frames != nil {
+		logMsg.Params = map[string]interface{}{StackKey: frames}
+	} else if !plainText {
+		logMsg.Params = map[string]interface{}{StackKey: string(stack)}
+	}
+	timestamp := root.formatTime(logMsg.Time)
+	root.settingsMutex.RLock()
+	record := root.prepareFileMessage(logMsg, timestamp, "", "")
+	root.settingsMutex.RUnlock()
+	if plainText {
+		record += "\n" + string(stack)
+	}
+
+	root.writeFile(logMsg.File, record)
+	root.flushFile(logMsg.File)
+}
@@ -0,0 +1,104 @@
+package asynclog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCapturePanicToFile(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []LoggerOption
+		want []string
+	}{
+		{"text", nil, []string{"FATAL: panic: boom", "goroutine "}},
+		{"structured json", []LoggerOption{SetStructuredJSON(true)}, []string{`"level":"FATAL"`, `"msg":"panic: boom"`, `"stack":`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, tt.opts...)
+			l.Info("before")
+
+			var repanicked interface{}
+			func() {
+				defer func() { repanicked = recover() }()
+				defer l.CapturePanicToFile()
+				panic("boom")
+			}()
+
+			if repanicked != "boom" {
+				t.Errorf("CapturePanicToFile re-panicked with %v, want boom", repanicked)
+			}
+			got := readFile(t, file)
+			for _, want := range append([]string{"before"}, tt.want...) {
+				if !strings.Contains(got, want) {
+					t.Errorf("file = %q, want it to contain %q", got, want)
+				}
+			}
+			// Queued messages are written before the panic
+			if strings.Index(got, "before") > strings.Index(got, "panic: boom") {
+				t.Errorf("queued message written after the panic: %q", got)
+			}
+		})
+	}
+}
+
+func TestCapturePanicToFileWithoutPanic(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous))
+
+	func() {
+		defer l.CapturePanicToFile()
+	}()
+
+	if got := readFile(t, file); got != "" {
+		t.Errorf("file = %q, want empty", got)
+	}
+}
+
+func TestLogPanicAfterClose(t *testing.T) {
+	var diags []string
+	l, file := newTestLogger(t, SetErrorHandler(func(err error) { diags = append(diags, err.Error()) }))
+	l.Info("before")
+	l.Close()
+
+	l.LogPanic("boom", []byte("goroutine 1 [running]:"))
+
+	if got := readFile(t, file); strings.Contains(got, "boom") {
+		t.Errorf("file = %q, want no panic record after Close", got)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0], "logger is closed") {
+		t.Errorf("diagnostics = %v, want one reporting the closed logger", diags)
+	}
+}
+
+func TestLogPanicConcurrentWithClose(t *testing.T) {
+	var mu sync.Mutex
+	var diags []string
+	l, file := newTestLogger(t, SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		diags = append(diags, err.Error())
+	}))
+
+	// The record is either written in full or dropped, never written to a closed file
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.LogPanic("boom", []byte("goroutine 1 [running]:"))
+	}()
+	l.Close()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	written := strings.Contains(readFile(t, file), "FATAL: panic: boom")
+	for _, diag := range diags {
+		if !strings.Contains(diag, "logger is closed") {
+			t.Errorf("unexpected diagnostic %q", diag)
+		}
+	}
+	if written == (len(diags) > 0) {
+		t.Errorf("panic record written = %v with diagnostics %v, want exactly one of them", written, diags)
+	}
+}
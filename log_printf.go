@@ -0,0 +1,36 @@
+package asynclog
+
+import "fmt"
+
+// Tracef logs a message at the Trace level, formatted like fmt.Sprintf.
+func (l *Logger) Tracef(format string, args ...interface{}) {
+	l.log(LogLevelTrace, fmt.Sprintf(format, args...))
+}
+
+// Debugf logs a message at the Debug level, formatted like fmt.Sprintf.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.log(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Infof logs a message at the Info level, formatted like fmt.Sprintf,
+// e.g. logger.Infof("listening on port %d", port).
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warningf logs a message at the Warning level, formatted like fmt.Sprintf.
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log(LogLevelWarning, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a message at the Error level, formatted like fmt.Sprintf.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatalf logs a message at the Fatal level, formatted like fmt.Sprintf,
+// and exits the process like Fatal.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.log(LogLevelFatal, fmt.Sprintf(format, args...))
+	l.exitFatal()
+}
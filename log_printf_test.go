@@ -0,0 +1,69 @@
+package asynclog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// thisLine returns the line number of its caller.
+func thisLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestPrintfMethods(t *testing.T) {
+	tests := []struct {
+		name  string
+		log   func(*Logger) int // Logs and returns the line of the log call.
+		want  string
+		exits bool
+	}{
+		{"trace", func(l *Logger) int {
+			l.Tracef("step %d of %d", 1, 3)
+			return thisLine() - 1
+		}, "TRACE: step 1 of 3", false},
+		{"debug", func(l *Logger) int {
+			l.Debugf("cache %s", "miss")
+			return thisLine() - 1
+		}, "DEBUG: cache miss", false},
+		{"info", func(l *Logger) int {
+			l.Infof("listening on port %d", 8080)
+			return thisLine() - 1
+		}, "INFO: listening on port 8080", false},
+		{"warning", func(l *Logger) int {
+			l.Warningf("retry %d/%d", 2, 5)
+			return thisLine() - 1
+		}, "WARNING: retry 2/5", false},
+		{"error", func(l *Logger) int {
+			l.Errorf("open %q: %v", "db", "denied")
+			return thisLine() - 1
+		}, `ERROR: open "db": denied`, false},
+		{"fatal", func(l *Logger) int {
+			l.Fatalf("giving up after %.1fs", 2.5)
+			return thisLine() - 1
+		}, "FATAL: giving up after 2.5s", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitCode := -1
+			l, file := newTestLogger(t, SetMode(Synchronous), SetFileLevel(LogLevelTrace),
+				EnableSourceInfo(true), SetExitFunc(func(code int) { exitCode = code }))
+
+			line := tt.log(l)
+
+			got := readFile(t, file)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			// The source is the caller's line, not the wrapper's
+			if source := fmt.Sprintf("log_printf_test.go:%d", line); !strings.Contains(got, source) {
+				t.Errorf("file = %q, want source %q", got, source)
+			}
+			if exited := exitCode == 1; exited != tt.exits {
+				t.Errorf("exit code = %d, want an exit with status 1: %v", exitCode, tt.exits)
+			}
+		})
+	}
+}
@@ -1,19 +1,116 @@
 package asynclog
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 // processLogs is the method that processes log messages.
-// This method runs in its own goroutine and handles messages sent to the LogChannel.
+// This method runs in its own goroutine and handles messages sent to the LogChannel
+// until the channel is closed by Close and drained.
 func (l *Logger) processLogs() {
+	defer l.workers.Done()
+
 	for logMessage := range l.LogChannel {
-		if l.OutputToFile && logMessage.Level >= l.FileLevel {
-			if logMessage.File == "" {
-				logMessage.File = l.DefaultFileName
+		// Skip the rest of the queue when closing without draining
+		if l.discard.Load() {
+			l.pending.Add(-1)
+			continue
+		}
+		if l.coalesceWindow > 0 {
+			l.processBatch(l.collectBatch(logMessage))
+			continue
+		}
+		l.processMessage(logMessage)
+		l.pending.Add(-1)
+	}
+}
+
+// processMessage writes a single log message to its file and console destinations.
+func (l *Logger) processMessage(logMessage LogMessage) {
+	l.settingsMutex.RLock()
+	defer l.settingsMutex.RUnlock()
+
+	logMessage = l.formatMessage(logMessage)
+	l.runHooks(logMessage)
+	l.runLevelCallbacks(logMessage)
+	if l.uiCallback != nil {
+		l.runUICallback(logMessage)
+	}
+
+	// Sign file records for tamper detection
+	if l.hmacKey != nil && logMessage.FileMessage != "" && l.fileFormat == FormatText {
+		logMessage.FileMessage = l.signRecord(logMessage.FileMessage)
+	}
+
+	written := ""
+	// The level of a single file is only known once the file name is resolved
+	if l.OutputToFile && (len(l.fileLevels) > 0 || l.wantsFile(logMessage.Level)) {
+		logMessage.File = l.resolveFileName(logMessage.File)
+		if l.wantsFileFor(logMessage.Level, logMessage.File) {
+			if !l.collapse || !l.collapseRepeat(logMessage) {
+				l.queueFileWrite(logMessage.File, logMessage.FileMessage, logMessage.Level.AtLeast(l.flushLevel))
 			}
-			l.writeFile(logMessage.File, logMessage.FileMessage)
+			written = logMessage.File
 		}
-		if l.OutputToConsole && logMessage.Level >= l.ConsoleLevel {
-			fmt.Println(logMessage.ConsoleMessage)
+	}
+	if l.routes != nil {
+		l.writeRoutes(logMessage, written)
+	}
+	if l.sink != nil && l.wantsFile(logMessage.Level) {
+		l.writeSink(logMessage.FileMessage)
+	}
+	printed := l.OutputToConsole && l.wantsConsole(logMessage.Level)
+	if printed {
+		l.printConsole(logMessage.Level, logMessage.ConsoleMessage)
+	}
+	// Print messages escalated with SetAlsoStderr, unless they just went to the same writer
+	if logMessage.alsoStderr {
+		if stderr := l.stderrOutput(); !printed || !sameWriter(l.consoleOutputFor(logMessage.Level), stderr) {
+			fmt.Fprintln(stderr, logMessage.ConsoleMessage)
 		}
 	}
 }
+
+// printConsole prints a console message, subject to the console rate limit.
+func (l *Logger) printConsole(level LogLevel, message string) {
+	if l.consoleLimit != nil {
+		allowed, note := l.consoleLimit.allow()
+		if note != "" {
+			fmt.Fprintln(l.consoleOutput(), note)
+		}
+		if !allowed {
+			return
+		}
+	}
+	if l.truncateConsole {
+		message = l.truncateConsoleMessage(message)
+	}
+	fmt.Fprintln(l.consoleOutputFor(level), message)
+}
+
+// consoleFile returns the standard stream used for console output.
+func (l *Logger) consoleFile() *os.File {
+	if l.consoleStream == Stderr {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// wantsFile reports whether messages of the given level go to file output.
+func (l *Logger) wantsFile(level LogLevel) bool {
+	return level.Below(LogLevelOff) && level.AtLeast(l.FileLevel) && l.levelDestination(level)&FileOnly != 0
+}
+
+// wantsConsole reports whether messages of the given level go to console output.
+func (l *Logger) wantsConsole(level LogLevel) bool {
+	return level.Below(LogLevelOff) && level.AtLeast(l.ConsoleLevel) && l.levelDestination(level)&ConsoleOnly != 0
+}
+
+// levelDestination returns the destination mask configured for the level.
+func (l *Logger) levelDestination(level LogLevel) Destination {
+	if dest, ok := l.levelDests[level]; ok {
+		return dest
+	}
+	return FileAndConsole
+}
@@ -0,0 +1,100 @@
+package asynclog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStructuredFieldsReachCallbacks(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetMode(tt.mode), SetUTC(true), EnableSourceInfo(true),
+				SetClock(func() time.Time { return at }))
+			received := make(chan LogMessage, 1)
+			l.OnLevel(LogLevelWarning, func(m LogMessage) { received <- m })
+
+			l.Named("db").Warning("slow query", SetEventName("db.slow"),
+				SetLogParams(map[string]interface{}{"rows": 3, "table": "users"}))
+			line := thisLine() - 2
+
+			var m LogMessage
+			select {
+			case m = <-received:
+			case <-time.After(time.Second):
+				t.Fatal("callback was not called")
+			}
+			if m.Level != LogLevelWarning || m.Message != "slow query" {
+				t.Errorf("level and message = %v %q, want Warning %q", m.Level, m.Message, "slow query")
+			}
+			if m.Params["rows"] != 3 || m.Params["table"] != "users" {
+				t.Errorf("params = %v, want the logged params", m.Params)
+			}
+			if !m.Time.Equal(at) {
+				t.Errorf("time = %v, want %v", m.Time, at)
+			}
+			if want := fmt.Sprintf("log_processor_test.go:%d", line); m.Source != want {
+				t.Errorf("source = %q, want %q", m.Source, want)
+			}
+			if m.Component != "db" || m.Event != "db.slow" {
+				t.Errorf("component and event = %q %q, want db db.slow", m.Component, m.Event)
+			}
+			// The formatted output travels along with the structured fields
+			if !strings.Contains(m.FileMessage, "WARNING: [db] slow query") {
+				t.Errorf("file message = %q, want the formatted line", m.FileMessage)
+			}
+		})
+	}
+}
+
+func TestSetLevelDestinations(t *testing.T) {
+	tests := []struct {
+		name        string
+		dest        Destination
+		wantFile    bool
+		wantConsole bool
+	}{
+		{"file only", FileOnly, true, false},
+		{"console only", ConsoleOnly, false, true},
+		{"both", FileAndConsole, true, true},
+		{"none", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			l, file := newTestLogger(t, SetMode(Synchronous), SetFileLevel(LogLevelDebug), SetConsoleLevel(LogLevelDebug),
+				EnableConsoleOutput(true), SetConsoleWriter(&console), SetLevelDestinations(LogLevelDebug, tt.dest))
+
+			l.Debug("details")
+			l.Info("started")
+
+			gotFile, gotConsole := readFile(t, file), console.String()
+			if strings.Contains(gotFile, "details") != tt.wantFile {
+				t.Errorf("file = %q, want Debug written: %v", gotFile, tt.wantFile)
+			}
+			if strings.Contains(gotConsole, "details") != tt.wantConsole {
+				t.Errorf("console = %q, want Debug printed: %v", gotConsole, tt.wantConsole)
+			}
+			// Other levels keep both outputs
+			if !strings.Contains(gotFile, "started") || !strings.Contains(gotConsole, "started") {
+				t.Errorf("file = %q, console = %q, want Info in both", gotFile, gotConsole)
+			}
+		})
+	}
+}
+
+func TestSetLevelDestinationsRejectsUnknownDestination(t *testing.T) {
+	if _, err := NewLogger(SetLevelDestinations(LogLevelDebug, 8)); err == nil {
+		t.Error("NewLogger accepted an unknown destination")
+	}
+}
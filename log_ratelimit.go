@@ -0,0 +1,69 @@
+package asynclog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// consoleLimiter limits console lines to a fixed number per second.
+type consoleLimiter struct {
+	mu          sync.Mutex // Mutex for synchronizing the limiter state.
+	perSecond   int        // Maximum console lines per second.
+	windowStart time.Time  // Start of the current one-second window.
+	count       int        // Lines printed in the current window.
+	suppressed  int        // Lines suppressed since the last note.
+}
+
+// SetConsoleRateLimit limits console output to perSecond lines per second.
+// Lines above the limit are suppressed and counted, and a
+// "console suppressed N lines" note is printed once the next window opens.
+// File output is not limited, so it remains complete.
+func SetConsoleRateLimit(perSecond int) LoggerOption {
+	return func(l *Logger) error {
+		if perSecond <= 0 {
+			return fmt.Errorf("console rate limit must be positive")
+		}
+		l.consoleLimit = &consoleLimiter{perSecond: perSecond}
+		return nil
+	}
+}
+
+// allow reports whether a console line may be printed now. When a new window
+// opens after lines were suppressed, it also returns the note to print first.
+func (c *consoleLimiter) allow() (bool, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var note string
+	now := time.Now()
+	if now.Sub(c.windowStart) >= time.Second {
+		note = c.noteLocked()
+		c.windowStart = now
+		c.count = 0
+	}
+	if c.count >= c.perSecond {
+		c.suppressed++
+		return false, note
+	}
+	c.count++
+	return true, note
+}
+
+// pendingNote returns the note for lines suppressed since the last note, if any.
+func (c *consoleLimiter) pendingNote() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.noteLocked()
+}
+
+// noteLocked returns the suppression note and resets the counter.
+func (c *consoleLimiter) noteLocked() string {
+	if c.suppressed == 0 {
+		return ""
+	}
+	note := fmt.Sprintf("console suppressed %d lines", c.suppressed)
+	c.suppressed = 0
+	return note
+}
@@ -0,0 +1,52 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetConsoleRateLimit(t *testing.T) {
+	var console syncBuffer
+	l, file := newTestLogger(t, SetMode(Synchronous), EnableConsoleOutput(true),
+		SetConsoleWriter(&console), SetConsoleRateLimit(2))
+
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		l.Info(msg)
+	}
+
+	if got := strings.Count(console.String(), "\n"); got != 2 {
+		t.Errorf("console has %d lines, want 2: %q", got, console.String())
+	}
+	if got := strings.Count(readFile(t, file), "INFO:"); got != 5 {
+		t.Errorf("file has %d messages, want all 5", got)
+	}
+
+	// The note is printed before the first line of the next window
+	l.consoleLimit.mu.Lock()
+	l.consoleLimit.windowStart = time.Now().Add(-time.Second)
+	l.consoleLimit.mu.Unlock()
+	l.Info("six")
+	l.Info("seven")
+	l.Info("eight")
+
+	lines := strings.Split(strings.TrimSuffix(console.String(), "\n"), "\n")
+	if len(lines) != 5 || lines[2] != "console suppressed 3 lines" ||
+		!strings.HasSuffix(lines[3], "INFO: six") || !strings.HasSuffix(lines[4], "INFO: seven") {
+		t.Fatalf("console = %q, want the note followed by the new window's lines", lines)
+	}
+
+	// Lines suppressed in the last window are noted on close
+	l.Close()
+	if got := console.String(); !strings.HasSuffix(got, "console suppressed 1 lines\n") {
+		t.Errorf("console = %q, want a final note on close", got)
+	}
+}
+
+func TestSetConsoleRateLimitRejectsNonPositive(t *testing.T) {
+	for _, perSecond := range []int{0, -1} {
+		if _, err := NewLogger(SetConsoleRateLimit(perSecond)); err == nil {
+			t.Errorf("NewLogger accepted a console rate limit of %d", perSecond)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package asynclog
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RedactedValue replaces the values of redacted params.
+const RedactedValue = "***"
+
+// SetRedactKeys masks the values of params with the given keys as RedactedValue
+// in all outputs, e.g. SetRedactKeys([]string{"password", "*token*"}).
+// Keys match case-insensitively, also inside nested param maps, and may be
+// patterns as accepted by path.Match. A matching nested map is masked as a whole.
+// Values are masked before they reach any formatter, callback or sidecar file;
+// fields inside struct values are not inspected.
+func SetRedactKeys(keys []string) LoggerOption {
+	return func(l *Logger) error {
+		patterns := make([]string, 0, len(keys))
+		for _, key := range keys {
+			pattern := strings.ToLower(key)
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid redact key %q: %w", key, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+		l.redactKeys = patterns
+		return nil
+	}
+}
+
+// isRedacted reports whether the value of a param key must be masked.
+// Keys renamed by ParamKeepBoth, e.g. "password#2", match as their original key.
+func (l *Logger) isRedacted(key string) bool {
+	key = strings.ToLower(stripCollisionSuffix(key))
+	for _, pattern := range l.redactKeys {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// redactParams returns the params with the values of redacted keys masked,
+// including those in nested param maps, and reports whether any was masked.
+// The original map is left untouched.
+func (l *Logger) redactParams(params map[string]interface{}) (map[string]interface{}, bool) {
+	var redacted map[string]interface{}
+	for key, value := range params {
+		var newValue interface{} = RedactedValue
+		if !l.isRedacted(key) {
+			nested, ok := nestedParams(value)
+			if !ok {
+				continue
+			}
+			group, changed := l.redactParams(nested)
+			if !changed {
+				continue
+			}
+			newValue = renest(value, group)
+		}
+
+		if redacted == nil {
+			redacted = make(map[string]interface{}, len(params))
+			for k, v := range params {
+				redacted[k] = v
+			}
+		}
+		redacted[key] = newValue
+	}
+	if redacted == nil {
+		return params, false
+	}
+	return redacted, true
+}
+
+// stripCollisionSuffix returns the key without the "#N" suffix added by ParamKeepBoth.
+func stripCollisionSuffix(key string) string {
+	i := strings.LastIndexByte(key, '#')
+	if i <= 0 || i == len(key)-1 {
+		return key
+	}
+	for _, c := range key[i+1:] {
+		if c < '0' || c > '9' {
+			return key
+		}
+	}
+	return key[:i]
+}
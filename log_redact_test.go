@@ -0,0 +1,88 @@
+package asynclog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSetRedactKeys(t *testing.T) {
+	params := map[string]interface{}{
+		"user":          "bob",
+		"Password":      "hunter2",
+		"access_token":  "tok-123",
+		"db":            map[string]interface{}{"host": "db1", "secret": "s3cr3t"},
+		"authorization": "Bearer abc",
+	}
+	tests := []struct {
+		name      string
+		formatter ParamFormatter
+		opts      []LoggerOption
+	}{
+		{"key-value", FormatParamsAsKeyValue, nil},
+		{"logfmt", FormatParamsAsLogfmt, nil},
+		{"json", FormatParamsAsJSON, nil},
+		{"structured json", FormatParamsAsKeyValue, []LoggerOption{SetStructuredJSON(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			var hooked LogMessage
+			opts := append([]LoggerOption{SetMode(Synchronous), SetParamFormatter(tt.formatter),
+				SetRedactKeys([]string{"password", "*token*", "secret", "AUTHORIZATION"}),
+				EnableConsoleOutput(true), SetConsoleWriter(&console)}, tt.opts...)
+			l, file := newTestLogger(t, opts...)
+			l.AddHook(func(m LogMessage) { hooked = m })
+
+			l.Info("login", SetLogParams(params))
+
+			for name, got := range map[string]string{"file": readFile(t, file), "console": console.String()} {
+				for _, secret := range []string{"hunter2", "tok-123", "s3cr3t", "Bearer"} {
+					if strings.Contains(got, secret) {
+						t.Errorf("%s = %q, want %q masked", name, got, secret)
+					}
+				}
+				for _, kept := range []string{"bob", "db1", RedactedValue} {
+					if !strings.Contains(got, kept) {
+						t.Errorf("%s = %q, want it to contain %q", name, got, kept)
+					}
+				}
+			}
+			if hooked.Params["Password"] != RedactedValue {
+				t.Errorf("hook received password %v, want it masked", hooked.Params["Password"])
+			}
+			// The caller's map is left untouched
+			if params["Password"] != "hunter2" {
+				t.Errorf("caller's params changed to %v", params)
+			}
+		})
+	}
+}
+
+func TestSetRedactKeysRejectsInvalidPattern(t *testing.T) {
+	if _, err := NewLogger(SetRedactKeys([]string{"[token"})); err == nil {
+		t.Error("NewLogger accepted an invalid pattern")
+	}
+}
+
+func TestSetRedactKeysWithKeepBoth(t *testing.T) {
+	var console bytes.Buffer
+	l, file := newTestLogger(t, SetMode(Synchronous), SetParamCollision(ParamKeepBoth),
+		SetRedactKeys([]string{"password"}), EnableConsoleOutput(true), SetConsoleWriter(&console))
+
+	l.WithFields(map[string]interface{}{"password": "secret1", "tag#1": "kept"}).
+		Info("login", SetLogParams(map[string]interface{}{"password": "secret2"}))
+
+	for name, got := range map[string]string{"file": readFile(t, file), "console": console.String()} {
+		for _, secret := range []string{"secret1", "secret2"} {
+			if strings.Contains(got, secret) {
+				t.Errorf("%s = %q, want %q masked", name, got, secret)
+			}
+		}
+		for _, want := range []string{`"password": ***`, `"password#2": ***`, `"tag#1": kept`} {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s = %q, want it to contain %q", name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package asynclog
+
+import "errors"
+
+// ReopenFiles closes and reopens all open log files, e.g. after an external tool
+// such as logrotate has renamed them, so that writing continues in new files
+// under the original names instead of the renamed ones. Buffered records are
+// written to the old files first. Files that cannot be reopened are reported
+// to the error handler and opened again on their next write; their errors are
+// joined into the returned error.
+func (l *Logger) ReopenFiles() error {
+	root := l.root()
+	root.fileMutex.Lock()
+	defer root.fileMutex.Unlock()
+
+	filenames := make([]string, 0, len(root.fileHandles))
+	for filename := range root.fileHandles {
+		filenames = append(filenames, filename)
+	}
+
+	var errs []error
+	for _, filename := range filenames {
+		root.closeFileLocked(filename)
+		// The new file starts counting from the beginning
+		delete(root.lineCounts, filename)
+		if _, err := root.openFileLocked(filename); err != nil {
+			root.recordWriteError(err)
+			root.diagf("Failed to reopen log file: %w", err)
+			errs = append(errs, err)
+			continue
+		}
+		root.fileAccessTimes[filename] = root.now()
+	}
+	return errors.Join(errs...)
+}
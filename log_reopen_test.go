@@ -0,0 +1,69 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReopenFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(tt.mode), SetLineNumbers(true))
+			l.Info("before")
+			l.WaitIdle(time.Second)
+
+			// Rename the file like logrotate does
+			rotated := file + ".1"
+			if err := os.Rename(file, rotated); err != nil {
+				t.Fatalf("Rename: %v", err)
+			}
+			if err := l.ReopenFiles(); err != nil {
+				t.Fatalf("ReopenFiles: %v", err)
+			}
+			l.Info("after")
+			l.Close()
+
+			if got := readFile(t, rotated); !strings.Contains(got, "INFO: before\n") || strings.Contains(got, "after") {
+				t.Errorf("rotated file = %q, want only the first message", got)
+			}
+			// The new file starts numbering from the beginning
+			if got := readFile(t, file); !strings.HasPrefix(got, "1 ") || !strings.Contains(got, "INFO: after\n") {
+				t.Errorf("new file = %q, want the second message numbered 1", got)
+			}
+		})
+	}
+}
+
+func TestReopenFilesFailure(t *testing.T) {
+	var diags []string
+	l, file := newTestLogger(t, SetMode(Synchronous),
+		SetErrorHandler(func(err error) { diags = append(diags, err.Error()) }))
+	l.Info("before")
+
+	// Replace the log directory with a file, so it cannot be recreated
+	dir := filepath.Dir(file)
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if err := os.WriteFile(dir, nil, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(dir) })
+
+	if err := l.ReopenFiles(); err == nil {
+		t.Error("ReopenFiles reported no error")
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0], "Failed to reopen log file") {
+		t.Errorf("diagnostics = %v, want one reporting the failed reopen", diags)
+	}
+}
@@ -0,0 +1,323 @@
+package asynclog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compressedSuffix is the file name suffix of compressed rotated files.
+const compressedSuffix = ".gz"
+
+// backupFileName returns the name of the n-th rotated generation of a log file.
+// Generation 1 is the newest, e.g. "app.log.1", followed by "app.log.2".
+func backupFileName(name string, n int) string {
+	return fmt.Sprintf("%s.%d", name, n)
+}
+
+// backupIndex returns the generation of a rotated file name belonging to baseName,
+// or false if the file name does not follow the rotation naming scheme.
+func backupIndex(baseName, fileName string) (int, bool) {
+	suffix, ok := strings.CutPrefix(fileName, baseName+".")
+	if !ok {
+		return 0, false
+	}
+	suffix = strings.TrimSuffix(suffix, compressedSuffix)
+	n, err := strconv.Atoi(suffix)
+	if err != nil || n <= 0 || strconv.Itoa(n) != suffix {
+		return 0, false
+	}
+	return n, true
+}
+
+// datedIndex returns the day and the generation of a daily file name belonging
+// to baseName, e.g. "app-2024-06-01.log" or its rotated "app-2024-06-01.log.1",
+// or false if the file name does not follow the daily naming scheme.
+// The day file itself is generation 0.
+func datedIndex(baseName, fileName string) (time.Time, int, bool) {
+	ext := filepath.Ext(baseName)
+	rest, ok := strings.CutPrefix(fileName, strings.TrimSuffix(baseName, ext)+"-")
+	if !ok || len(rest) < len(dateLayout) {
+		return time.Time{}, 0, false
+	}
+	date, err := time.Parse(dateLayout, rest[:len(dateLayout)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	dated := datedFileName(baseName, date)
+	if fileName == dated || fileName == dated+compressedSuffix {
+		return date, 0, true
+	}
+	n, ok := backupIndex(dated, fileName)
+	return date, n, ok
+}
+
+// RotatedFiles returns the rotated generations of the logical log file baseName,
+// ordered from newest to oldest, including compressed generations.
+// The files of daily rotation are listed first, newest day first, each followed
+// by its own generations, the current day's file included.
+// The file baseName itself is not included.
+func RotatedFiles(baseName string) ([]string, error) {
+	dir, base := filepath.Split(baseName)
+	entries, err := os.ReadDir(filepath.Clean(dir + "."))
+	if err != nil {
+		return nil, err
+	}
+
+	type generation struct {
+		name  string
+		date  time.Time // Day of a daily file, zero for others.
+		index int
+	}
+	var generations []generation
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if n, ok := backupIndex(base, entry.Name()); ok {
+			generations = append(generations, generation{name: dir + entry.Name(), index: n})
+		} else if date, n, ok := datedIndex(base, entry.Name()); ok {
+			generations = append(generations, generation{name: dir + entry.Name(), date: date, index: n})
+		}
+	}
+
+	sort.Slice(generations, func(i, j int) bool {
+		if !generations[i].date.Equal(generations[j].date) {
+			return generations[i].date.After(generations[j].date)
+		}
+		if generations[i].index != generations[j].index {
+			return generations[i].index < generations[j].index
+		}
+		return generations[i].name < generations[j].name
+	})
+
+	files := make([]string, len(generations))
+	for i, g := range generations {
+		files[i] = g.name
+	}
+	return files, nil
+}
+
+// SetMaxFileSize rotates a log file before a write that would make it larger
+// than maxBytes: the file is renamed to "name.1", older generations are shifted
+// to "name.2", "name.3" and so on, and a new file is started. A single write
+// larger than the limit still goes to a fresh file in one piece.
+func SetMaxFileSize(maxBytes int64) LoggerOption {
+	return func(l *Logger) error {
+		if maxBytes <= 0 {
+			return fmt.Errorf("max file size must be positive")
+		}
+		l.maxFileSize = maxBytes
+		return nil
+	}
+}
+
+// SetMaxBackups limits the number of rotated generations kept per log file.
+// Older generations are deleted on rotation. With daily rotation, it also
+// limits the number of previous days kept, whose files are deleted when the
+// day changes. By default all are kept.
+func SetMaxBackups(n int) LoggerOption {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return fmt.Errorf("max backups must be positive")
+		}
+		l.maxBackups = n
+		return nil
+	}
+}
+
+// trackFileSizesLocked starts tracking the sizes of the open files, e.g. when
+// a size limit is set at runtime. The caller must hold fileMutex.
+func (l *Logger) trackFileSizesLocked() {
+	for filename, file := range l.fileHandles {
+		if file == nil {
+			continue
+		}
+		if info, err := file.Stat(); err == nil {
+			l.fileSizes[filename] = info.Size()
+		}
+	}
+}
+
+// rotateIfFull rotates the file if writing size more bytes would exceed the size limit.
+// It reports whether the file was rotated. The caller must hold fileMutex.
+func (l *Logger) rotateIfFull(filename string, size int64) bool {
+	current, ok := l.fileSizes[filename]
+	if !ok || l.fileHandles[filename] == nil {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return false
+		}
+		current = info.Size()
+	}
+	if current == 0 || current+size <= l.maxFileSize {
+		return false
+	}
+	if err := l.rotateFileLocked(filename); err != nil {
+		l.recordWriteError(err)
+		l.diagf("Failed to rotate log file: %w", err)
+		return false
+	}
+	return true
+}
+
+// rotateFileLocked closes a log file, shifts its rotated generations and renames
+// it to the first generation, so the next write starts a new file.
+// The caller must hold fileMutex.
+func (l *Logger) rotateFileLocked(filename string) error {
+	l.closeFileLocked(filename)
+
+	// Shift the generations, oldest first, dropping those beyond the limit
+	generations, err := RotatedFiles(filename)
+	if err != nil {
+		return err
+	}
+	renames := make(map[string]string, len(generations))
+	for i := len(generations) - 1; i >= 0; i-- {
+		name := generations[i]
+		n, ok := backupIndex(filepath.Base(filename), filepath.Base(name))
+		if !ok {
+			// Daily files are pruned by day instead
+			continue
+		}
+		if l.maxBackups > 0 && n >= l.maxBackups {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+			renames[name] = ""
+			continue
+		}
+		suffix := ""
+		if strings.HasSuffix(name, compressedSuffix) {
+			suffix = compressedSuffix
+		}
+		renamed := backupFileName(filename, n+1) + suffix
+		if err := os.Rename(name, renamed); err != nil {
+			return err
+		}
+		renames[name] = renamed
+	}
+	if err := os.Rename(filename, backupFileName(filename, 1)); err != nil {
+		return err
+	}
+
+	// Record the rotation in the manifest
+	if l.writeManifest {
+		if err := l.updateManifestLocked(filename, renames); err != nil {
+			l.diagf("Failed to update log manifest: %w", err)
+		}
+	}
+
+	// The new file starts counting from the beginning
+	delete(l.lineCounts, filename)
+	return nil
+}
+
+// SetDailyRotation starts a new log file every calendar day by inserting the
+// current date into file names, e.g. "app.log" is written as "app-2024-06-01.log".
+// The date boundary follows the time zone set with SetTimeLocation. When the
+// date changes, the previous day's file is closed.
+func SetDailyRotation(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.dailyRotation = enable
+		return nil
+	}
+}
+
+// dateLayout is the layout of the date in the file names of daily rotation.
+const dateLayout = "2006-01-02"
+
+// datedFileName returns the file of the current day for a log file name.
+func datedFileName(name string, date time.Time) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-" + date.Format(dateLayout) + ext
+}
+
+// currentDatedFile returns today's file for a log file name and closes the file
+// of the previous day once the date has changed. The caller must hold fileMutex.
+func (l *Logger) currentDatedFile(name string) string {
+	now := l.now().In(l.timeLocation())
+	dated := datedFileName(name, now)
+	previous, ok := l.datedFiles[name]
+	if ok && previous == dated {
+		return dated
+	}
+	if ok {
+		l.closeFileLocked(previous)
+	}
+	l.datedFiles[name] = dated
+	if l.maxBackups > 0 {
+		l.pruneDatedFilesLocked(name, now.Format(dateLayout))
+	}
+	return dated
+}
+
+// pruneDatedFilesLocked deletes the daily files of a log file name, with their
+// generations, beyond the maxBackups most recent days before today.
+// The caller must hold fileMutex.
+func (l *Logger) pruneDatedFilesLocked(name, today string) {
+	files, err := RotatedFiles(name)
+	if err != nil {
+		l.diagf("Failed to list daily log files: %w", err)
+		return
+	}
+	days := 0
+	last := ""
+	removed := make(map[string]map[string]bool) // Deleted files by the dated file they belong to.
+	for _, file := range files {
+		date, _, ok := datedIndex(filepath.Base(name), filepath.Base(file))
+		day := date.Format(dateLayout)
+		if !ok || day >= today {
+			continue
+		}
+		if day != last {
+			days, last = days+1, day
+		}
+		if days <= l.maxBackups {
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			l.diagf("Failed to delete old daily log file: %w", err)
+			continue
+		}
+		dated := datedFileName(name, date)
+		if removed[dated] == nil {
+			removed[dated] = make(map[string]bool)
+		}
+		removed[dated][filepath.Base(file)] = true
+	}
+
+	// Drop the deleted files from the manifests of their days
+	if l.writeManifest {
+		for dated, files := range removed {
+			if err := l.pruneManifestLocked(dated, files); err != nil {
+				l.diagf("Failed to update log manifest: %w", err)
+			}
+		}
+	}
+}
+
+// closeFileLocked closes an open log file and forgets its handle.
+// The caller must hold fileMutex.
+func (l *Logger) closeFileLocked(filename string) {
+	file := l.fileHandles[filename]
+	if file != nil {
+		l.flushBufferLocked(filename)
+		if l.flushCounts[filename] > 0 {
+			l.flushFileLocked(filename)
+		}
+		if err := file.Close(); err != nil {
+			l.diagf("Failed to close log file: %w", err)
+		}
+	}
+	delete(l.fileHandles, filename)
+	delete(l.fileAccessTimes, filename)
+	delete(l.fileSizes, filename)
+	delete(l.flushCounts, filename)
+	delete(l.buffers, filename)
+	l.lastHandle = nil
+}
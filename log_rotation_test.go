@@ -0,0 +1,143 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSizeRotation(t *testing.T) {
+	clock := func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name    string
+		opts    []LoggerOption
+		maxSize int64
+		n       int
+	}{
+		// Plain records take 36 bytes, so three fit in a file
+		{"plain", nil, 110, 9},
+		// Numbered records take 38 bytes, so only two fit, while three
+		// would if the record were sized before it is numbered
+		{"line numbers", []LoggerOption{SetLineNumbers(true)}, 112, 6},
+		{"line numbers and markers", []LoggerOption{SetLineNumbers(true), SetRecordMarkers(">> ", ".. ")}, 120, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]LoggerOption{SetMode(Synchronous), SetClock(clock), SetMaxFileSize(tt.maxSize)}, tt.opts...)
+			l, file := newTestLogger(t, opts...)
+			for i := 0; i < tt.n; i++ {
+				l.Info("message")
+			}
+			l.Close()
+
+			// Two rotations leave the file and two generations
+			lines := 0
+			for _, name := range []string{file, file + ".1", file + ".2"} {
+				info, err := os.Stat(name)
+				if err != nil {
+					t.Fatalf("Stat: %v", err)
+				}
+				if info.Size() > tt.maxSize {
+					t.Errorf("%s has %d bytes, want at most %d", name, info.Size(), tt.maxSize)
+				}
+				lines += strings.Count(readFile(t, name), "INFO: message\n")
+			}
+			if _, err := os.Stat(file + ".3"); !os.IsNotExist(err) {
+				t.Errorf("unexpected third generation, Stat error = %v", err)
+			}
+			if lines != tt.n {
+				t.Errorf("files hold %d lines, want %d", lines, tt.n)
+			}
+		})
+	}
+}
+
+// testClock is a clock tests can move forward while a logger reads it.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestDailyRotation(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxBackups int
+		days       int
+		want       []string
+	}{
+		{"past midnight", 0, 2, []string{"app-2024-06-02.log", "app-2024-06-01.log"}},
+		{"all days kept", 0, 4, []string{"app-2024-06-04.log", "app-2024-06-03.log", "app-2024-06-02.log", "app-2024-06-01.log"}},
+		{"old days pruned", 1, 4, []string{"app-2024-06-04.log", "app-2024-06-03.log"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: time.Date(2024, 6, 1, 23, 59, 0, 0, time.UTC)}
+			opts := []LoggerOption{SetMode(Synchronous), SetClock(clock.Now), SetUTC(true), SetDailyRotation(true)}
+			if tt.maxBackups > 0 {
+				opts = append(opts, SetMaxBackups(tt.maxBackups))
+			}
+			l, file := newTestLogger(t, opts...)
+			for day := 0; day < tt.days; day++ {
+				l.Info("message")
+				clock.Add(24 * time.Hour)
+			}
+
+			files, err := RotatedFiles(file)
+			if err != nil {
+				t.Fatalf("RotatedFiles: %v", err)
+			}
+			got := make([]string, len(files))
+			for i, name := range files {
+				got[i] = filepath.Base(name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RotatedFiles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotatedFilesOrder(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"app.log", "app.log.2", "app.log.1.gz", "app-2024-06-01.log", "app-2024-06-02.log",
+		"app-2024-06-01.log.1", "app-2024-06-01.log.2.gz", "app.log.x", "app-2024-13-01.log", "other.log.1",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	files, err := RotatedFiles(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatalf("RotatedFiles: %v", err)
+	}
+	want := []string{
+		"app-2024-06-02.log", "app-2024-06-01.log", "app-2024-06-01.log.1",
+		"app-2024-06-01.log.2.gz", "app.log.1.gz", "app.log.2",
+	}
+	got := make([]string, len(files))
+	for i, name := range files {
+		got[i] = filepath.Base(name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RotatedFiles = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package asynclog
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// fileRoute is an additional file receiving every message at or above its own level.
+type fileRoute struct {
+	file     string   // Target log file.
+	minLevel LogLevel // Minimum level of messages copied to the file.
+}
+
+// RouteToFile copies every message at or above minLevel to the given file,
+// in addition to the file it is logged to, e.g. to keep a verbose debug.log
+// next to an app.log that only receives Info and above:
+//
+//	SetFileLevel(LogLevelInfo), RouteToFile("debug.log", LogLevelTrace)
+//
+// The level of a route replaces the global FileLevel gate for that file, so
+// a route may receive messages that FileLevel keeps out of all other files.
+// Routes are disabled together with file output and honor the level
+// destinations set with SetLevelDestinations. Several routes may be added.
+func RouteToFile(fileName string, minLevel LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		if fileName == "" {
+			return fmt.Errorf("route file name must not be empty")
+		}
+		l.routes = append(l.routes, fileRoute{file: fileName, minLevel: minLevel})
+		return nil
+	}
+}
+
+// routeWants reports whether the route receives messages of the given level.
+func (l *Logger) routeWants(route fileRoute, level LogLevel) bool {
+	return l.OutputToFile && level.Below(LogLevelOff) && level.AtLeast(route.minLevel) &&
+		l.levelDestination(level)&FileOnly != 0
+}
+
+// wantsRoute reports whether messages of the given level go to any route.
+func (l *Logger) wantsRoute(level LogLevel) bool {
+	for _, route := range l.routes {
+		if l.routeWants(route, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRoutes queues a file record for the routes receiving its level,
+// skipping the file the record has already been written to, if any.
+func (l *Logger) writeRoutes(logMessage LogMessage, written string) {
+	for _, route := range l.routes {
+		if route.file == written || !l.routeWants(route, logMessage.Level) {
+			continue
+		}
+		l.queueFileWrite(route.file, logMessage.FileMessage, logMessage.Level.AtLeast(l.flushLevel))
+	}
+}
+
+// SetFileLevelFor sets the minimum level of messages written to a single file,
+// overriding the global FileLevel for messages logged to it, e.g. to let
+// debug.log receive everything while other files only receive Info and above:
+//
+//	SetFileLevel(LogLevelInfo), SetFileLevelFor("debug.log", LogLevelTrace)
+//
+// Unlike RouteToFile it does not copy messages logged to other files.
+// Files without their own level use FileLevel.
+func SetFileLevelFor(fileName string, level LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		if fileName == "" {
+			return fmt.Errorf("file name must not be empty")
+		}
+		if l.fileLevels == nil {
+			l.fileLevels = make(map[string]LogLevel)
+		}
+		l.fileLevels[filepath.Clean(fileName)] = level
+		return nil
+	}
+}
+
+// wantsFileFor reports whether messages of the given level are written to the file.
+func (l *Logger) wantsFileFor(level LogLevel, file string) bool {
+	if len(l.fileLevels) == 0 {
+		return l.wantsFile(level)
+	}
+	minLevel, ok := l.fileLevels[filepath.Clean(file)]
+	if !ok {
+		return l.wantsFile(level)
+	}
+	return level.Below(LogLevelOff) && level.AtLeast(minLevel) && l.levelDestination(level)&FileOnly != 0
+}
+
+// wantsAnyFile reports whether messages of the given level may be written to
+// any file: by the global FileLevel, a route or the level of a single file.
+func (l *Logger) wantsAnyFile(level LogLevel) bool {
+	if l.wantsFile(level) || l.wantsRoute(level) {
+		return true
+	}
+	for file := range l.fileLevels {
+		if l.wantsFileFor(level, file) {
+			return true
+		}
+	}
+	return false
+}
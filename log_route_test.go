@@ -0,0 +1,43 @@
+package asynclog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetFileLevelFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		level LogLevel
+		file  string
+		want  bool
+	}{
+		{"file with a lower level", LogLevelDebug, "debug.log", true},
+		{"file gated at Info", LogLevelDebug, "access.log", false},
+		{"file without its own level", LogLevelDebug, "other.log", false},
+		{"Info reaches the gated file", LogLevelInfo, "access.log", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetFileNameSanitizer(acceptFileName),
+				SetFileLevel(LogLevelWarning),
+				SetFileLevelFor(filepath.Join(dir, "debug.log"), LogLevelTrace),
+				SetFileLevelFor(filepath.Join(dir, "access.log"), LogLevelInfo))
+
+			file := filepath.Join(dir, tt.file)
+			l.log(tt.level, "message", SetLogFile(file))
+
+			if got := strings.Contains(readFile(t, file), ": message\n"); got != tt.want {
+				t.Errorf("message written to %s = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFileLevelForRejectsEmptyName(t *testing.T) {
+	if _, err := NewLogger(SetFileLevelFor("", LogLevelDebug)); err == nil {
+		t.Error("NewLogger accepted an empty file name")
+	}
+}
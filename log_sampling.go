@@ -0,0 +1,54 @@
+package asynclog
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// sampler lets through one of every n messages of each level.
+type sampler struct {
+	every  uint64                     // Sampling interval n.
+	counts [LogLevelOff]atomic.Uint64 // Messages seen per level.
+}
+
+// SetSampling logs only one of every n messages of each level, starting with
+// the first, e.g. to keep a noisy loop from flooding the logs. Levels are
+// counted separately, and Fatal messages are never sampled. Messages are
+// dropped in the log call before they are formatted or queued, and counted
+// by SampledCount.
+func SetSampling(n int) LoggerOption {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return fmt.Errorf("sampling interval must be positive")
+		}
+		l.sampling = &sampler{every: uint64(n)}
+		return nil
+	}
+}
+
+// allow reports whether a message of the given level is let through.
+func (s *sampler) allow(level LogLevel) bool {
+	if s.every == 1 || level < LogLevelTrace || level >= LogLevelFatal {
+		return true
+	}
+	return (s.counts[level].Add(1)-1)%s.every == 0
+}
+
+// sampledOut reports whether a message of the given level is dropped by
+// sampling, counting it if so. Messages not logged at all are not counted.
+// The caller must hold settingsMutex for reading.
+func (l *Logger) sampledOut(level LogLevel) bool {
+	if !l.wantsAnyFile(level) && !l.wantsConsole(level) {
+		return false
+	}
+	if l.sampling.allow(level) {
+		return false
+	}
+	l.sampled.Add(1)
+	return true
+}
+
+// SampledCount returns the number of messages dropped by SetSampling.
+func (l *Logger) SampledCount() uint64 {
+	return l.root().sampled.Load()
+}
@@ -0,0 +1,85 @@
+package asynclog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetSampling(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		k    int
+	}{
+		{"every message", 1, 5},
+		{"one of two", 2, 5},
+		{"one of ten", 10, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetSampling(tt.n))
+
+			for i := 0; i < tt.k*tt.n; i++ {
+				l.Info("message")
+			}
+
+			if got := strings.Count(readFile(t, file), "INFO: message\n"); got != tt.k {
+				t.Errorf("file has %d lines, want %d", got, tt.k)
+			}
+			if got, want := l.SampledCount(), uint64(tt.k*(tt.n-1)); got != want {
+				t.Errorf("SampledCount = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestSamplingPerLevel(t *testing.T) {
+	var exits int
+	l, file := newTestLogger(t, SetMode(Synchronous), SetFileLevel(LogLevelInfo), SetConsoleLevel(LogLevelInfo),
+		SetSampling(3), SetExitFunc(func(int) { exits++ }))
+
+	// Levels are counted separately, and messages below the file level are not counted
+	for i := 0; i < 3; i++ {
+		l.Info("info")
+		l.Error("error")
+		l.Debug("debug")
+	}
+	l.Fatal("fatal")
+
+	got := readFile(t, file)
+	for _, want := range []string{"INFO: info\n", "ERROR: error\n", "FATAL: fatal\n"} {
+		if strings.Count(got, want) != 1 {
+			t.Errorf("file = %q, want %q once", got, want)
+		}
+	}
+	if got := l.SampledCount(); got != 4 {
+		t.Errorf("SampledCount = %d, want 4", got)
+	}
+}
+
+func TestSamplingConcurrentCallers(t *testing.T) {
+	l, file := newTestLogger(t, SetSampling(4))
+
+	const goroutines, perGoroutine = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				l.Info("message")
+			}
+		}()
+	}
+	wg.Wait()
+	l.Close()
+
+	emitted := strings.Count(readFile(t, file), "INFO: message\n")
+	if emitted != goroutines*perGoroutine/4 {
+		t.Errorf("file has %d lines, want %d", emitted, goroutines*perGoroutine/4)
+	}
+	if got := l.SampledCount(); got != uint64(goroutines*perGoroutine-emitted) {
+		t.Errorf("SampledCount = %d, want %d", got, goroutines*perGoroutine-emitted)
+	}
+}
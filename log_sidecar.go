@@ -0,0 +1,134 @@
+package asynclog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// SidecarPrefix precedes the path of a sidecar file in the param it replaces.
+	SidecarPrefix = "sidecar:"
+
+	// sidecarSuffix is the extension of sidecar files.
+	sidecarSuffix = ".sidecar"
+
+	// DefaultSidecarRetention is how long sidecar files are kept before they are removed.
+	DefaultSidecarRetention = 7 * 24 * time.Hour
+)
+
+// SetLargeValueSidecar writes string and []byte param values longer than
+// threshold bytes to their own file in dir, and logs "sidecar:<path>" in their
+// place, so multi-megabyte blobs neither bloat the log nor slow down formatting.
+// Sidecar files are named "<time>-<sequence>-<param key>.sidecar", e.g.
+// "20240601T120000.123-000042-body.sidecar". The cleanup routine of an
+// asynchronous logger removes sidecar files in dir after DefaultSidecarRetention.
+// If a sidecar file cannot be written, the value is logged inline.
+func SetLargeValueSidecar(threshold int, dir string) LoggerOption {
+	return func(l *Logger) error {
+		if threshold <= 0 {
+			return fmt.Errorf("sidecar threshold must be positive")
+		}
+		if dir == "" {
+			return fmt.Errorf("sidecar directory must not be empty")
+		}
+		l.sidecarSize = threshold
+		l.sidecarDir = dir
+		return nil
+	}
+}
+
+// moveLargeValues returns the params with large values, including those in
+// nested param maps, replaced by references to sidecar files.
+// The original map is left untouched.
+func (l *Logger) moveLargeValues(params map[string]interface{}, prefix string) (map[string]interface{}, bool) {
+	var moved map[string]interface{}
+	for key, value := range params {
+		var newValue interface{}
+		switch v := value.(type) {
+		case string:
+			if len(v) <= l.sidecarSize {
+				continue
+			}
+			newValue = l.writeSidecar(prefix+key, []byte(v), value)
+		case []byte:
+			if len(v) <= l.sidecarSize {
+				continue
+			}
+			newValue = l.writeSidecar(prefix+key, v, value)
+		default:
+			nested, ok := nestedParams(value)
+			if !ok {
+				continue
+			}
+			group, changed := l.moveLargeValues(nested, prefix+key+".")
+			if !changed {
+				continue
+			}
+			newValue = renest(value, group)
+		}
+
+		if moved == nil {
+			moved = make(map[string]interface{}, len(params))
+			for k, v := range params {
+				moved[k] = v
+			}
+		}
+		moved[key] = newValue
+	}
+	if moved == nil {
+		return params, false
+	}
+	return moved, true
+}
+
+// writeSidecar writes a value to a new sidecar file and returns its reference,
+// or the original value if the file cannot be written.
+func (l *Logger) writeSidecar(key string, data []byte, original interface{}) interface{} {
+	name := fmt.Sprintf("%s-%06d-%s%s", l.now().Format("20060102T150405.000"),
+		l.sidecarSeq.Add(1), sanitizeSidecarKey(key), sidecarSuffix)
+	path := filepath.Join(l.sidecarDir, name)
+
+	if err := os.MkdirAll(l.sidecarDir, l.dirPerm); err != nil {
+		l.diagf("Failed to write sidecar file: %w", err)
+		return original
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		l.diagf("Failed to write sidecar file: %w", err)
+		return original
+	}
+	return SidecarPrefix + path
+}
+
+// sanitizeSidecarKey replaces the characters of a param key that are unsafe in file names.
+func sanitizeSidecarKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// cleanupSidecars removes sidecar files older than DefaultSidecarRetention.
+func (l *Logger) cleanupSidecars() {
+	entries, err := os.ReadDir(l.sidecarDir)
+	if err != nil {
+		return
+	}
+	threshold := time.Now().Add(-DefaultSidecarRetention)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), sidecarSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(threshold) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(l.sidecarDir, entry.Name())); err != nil {
+			l.diagf("Failed to remove sidecar file: %w", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetLargeValueSidecar(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 123e6, time.UTC)
+	dir := filepath.Join(t.TempDir(), "sidecars")
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(func() time.Time { return at }),
+		SetLargeValueSidecar(8, dir))
+	body := strings.Repeat("x", 9)
+
+	l.Info("request", SetLogParams(map[string]interface{}{"body": body, "id": "short"}))
+	l.Info("upload", SetLogParams(map[string]interface{}{"req": map[string]interface{}{"raw data": []byte(body)}}))
+
+	first := filepath.Join(dir, "20240601T120000.123-000001-body.sidecar")
+	second := filepath.Join(dir, "20240601T120000.123-000002-req.raw_data.sidecar")
+	got := readFile(t, file)
+	for _, want := range []string{`"body": ` + SidecarPrefix + first, `"id": short`, SidecarPrefix + second} {
+		if !strings.Contains(got, want) {
+			t.Errorf("file = %q, want it to contain %q", got, want)
+		}
+	}
+	for _, name := range []string{first, second} {
+		if data := readFile(t, name); data != body {
+			t.Errorf("sidecar %s = %q, want the value", filepath.Base(name), data)
+		}
+	}
+}
+
+func TestCleanupSidecars(t *testing.T) {
+	dir := t.TempDir()
+	l, _ := newTestLogger(t, SetLargeValueSidecar(8, dir))
+	old := time.Now().Add(-DefaultSidecarRetention - time.Hour)
+	files := []struct {
+		name    string
+		old     bool
+		removed bool
+	}{
+		{"old.sidecar", true, true},
+		{"recent.sidecar", false, false},
+		{"old.txt", true, false},
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		if f.old {
+			if err := os.Chtimes(path, old, old); err != nil {
+				t.Fatalf("Chtimes: %v", err)
+			}
+		}
+	}
+
+	l.cleanupSidecars()
+
+	for _, f := range files {
+		_, err := os.Stat(filepath.Join(dir, f.name))
+		if removed := os.IsNotExist(err); removed != f.removed {
+			t.Errorf("%s removed = %v, want %v", f.name, removed, f.removed)
+		}
+	}
+}
+
+func TestSetLargeValueSidecarRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		threshold int
+		dir       string
+	}{
+		{0, "sidecars"},
+		{-1, "sidecars"},
+		{1024, ""},
+	}
+	for _, tt := range tests {
+		if _, err := NewLogger(SetLargeValueSidecar(tt.threshold, tt.dir)); err == nil {
+			t.Errorf("NewLogger accepted a sidecar threshold of %d in %q", tt.threshold, tt.dir)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package asynclog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// minSinkBackoff is the initial delay before redialing a failed sink.
+	minSinkBackoff = 100 * time.Millisecond
+
+	// maxSinkBackoff is the maximum delay before redialing a failed sink.
+	maxSinkBackoff = 30 * time.Second
+
+	// sinkWriteTimeout bounds a single write to a sink connection, so a
+	// stalled peer cannot block the log processor.
+	sinkWriteTimeout = 5 * time.Second
+)
+
+// Framing defines how records written to a sink are delimited.
+type Framing int
+
+const (
+	// NewlineFraming terminates each record with a newline.
+	NewlineFraming Framing = iota
+
+	// LengthPrefixed precedes each record with its length as a 4-byte
+	// big-endian integer, so records may safely contain newlines.
+	LengthPrefixed
+)
+
+// frameMessage returns the message delimited according to the framing.
+func frameMessage(framing Framing, message string) []byte {
+	if framing == LengthPrefixed {
+		frame := make([]byte, 4+len(message))
+		binary.BigEndian.PutUint32(frame, uint32(len(message)))
+		copy(frame[4:], message)
+		return frame
+	}
+	return []byte(message + "\n")
+}
+
+// errSinkUnavailable is returned while a sink waits to redial its destination.
+var errSinkUnavailable = errors.New("log sink unavailable")
+
+// writeDeadliner is implemented by connections supporting write deadlines, such as net.Conn.
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// reconnectWriter is an io.Writer for network-like sinks.
+// It dials lazily, redials with exponential backoff after failures,
+// and counts the messages dropped while the destination is unavailable.
+// Writes time out after sinkWriteTimeout on connections supporting deadlines.
+type reconnectWriter struct {
+	dial     func() (io.WriteCloser, error) // Function opening a new connection.
+	mu       sync.Mutex                     // Mutex for synchronizing connection access.
+	conn     io.WriteCloser                 // Current connection, nil when disconnected.
+	backoff  time.Duration                  // Delay before the next dial attempt.
+	nextDial time.Time                      // Earliest time of the next dial attempt.
+	dropped  atomic.Uint64                  // Number of messages dropped.
+}
+
+// newReconnectWriter creates a reconnectWriter using the given dial function.
+func newReconnectWriter(dial func() (io.WriteCloser, error)) *reconnectWriter {
+	return &reconnectWriter{dial: dial}
+}
+
+// Write writes p to the current connection, dialing first if needed.
+// If the destination is unavailable the message is dropped and counted.
+func (w *reconnectWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if time.Now().Before(w.nextDial) {
+			w.dropped.Add(1)
+			return 0, errSinkUnavailable
+		}
+		conn, err := w.dial()
+		if err != nil {
+			w.failLocked()
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	if conn, ok := w.conn.(writeDeadliner); ok {
+		conn.SetWriteDeadline(time.Now().Add(sinkWriteTimeout))
+	}
+	n, err := w.conn.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		// The peer may have received part of the record, so the stream is
+		// only in sync again on a new connection
+		w.conn.Close()
+		w.conn = nil
+		w.failLocked()
+		return n, err
+	}
+
+	w.backoff = 0
+	return n, nil
+}
+
+// failLocked counts a dropped message and schedules the next dial attempt.
+func (w *reconnectWriter) failLocked() {
+	w.dropped.Add(1)
+	if w.backoff == 0 {
+		w.backoff = minSinkBackoff
+	} else if w.backoff *= 2; w.backoff > maxSinkBackoff {
+		w.backoff = maxSinkBackoff
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+}
+
+// Dropped returns the number of messages dropped by the writer.
+func (w *reconnectWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close closes the current connection, if any.
+func (w *reconnectWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// writeSink writes a formatted file line to the configured sink.
+func (l *Logger) writeSink(message string) {
+	if _, err := l.sink.Write(frameMessage(l.framing, message)); err != nil && !errors.Is(err, errSinkUnavailable) {
+		l.recordWriteError(err)
+		l.diagf("Error writing to log sink: %w", err)
+	}
+}
+
+// SetFraming sets how records written to sinks are delimited.
+// Newline framing is the default.
+func SetFraming(framing Framing) LoggerOption {
+	return func(l *Logger) error {
+		if framing != NewlineFraming && framing != LengthPrefixed {
+			return fmt.Errorf("unknown framing: %d", framing)
+		}
+		l.framing = framing
+		return nil
+	}
+}
+
+// SinkDropped returns the number of messages dropped because the sink was unavailable.
+func (l *Logger) SinkDropped() uint64 {
+	root := l.root()
+	if root.sink == nil {
+		return 0
+	}
+	return root.sink.Dropped()
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package asynclog
+
+import "fmt"
+
+// SetUnixSocketSink is not supported on this platform and always returns an error.
+func SetUnixSocketSink(path string) LoggerOption {
+	return func(l *Logger) error {
+		return fmt.Errorf("unix socket sink is not supported on this platform")
+	}
+}
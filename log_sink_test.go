@@ -0,0 +1,146 @@
+package asynclog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeConn is a sink connection accepting at most limit bytes per write.
+type fakeConn struct {
+	buf      bytes.Buffer
+	limit    int
+	closed   bool
+	deadline time.Time
+}
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	if c.limit > 0 && len(p) > c.limit {
+		p = p[:c.limit]
+	}
+	return c.buf.Write(p)
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) SetWriteDeadline(t time.Time) error {
+	c.deadline = t
+	return nil
+}
+
+func TestReconnectWriter(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		wantErr     error
+		wantClosed  bool
+		wantDropped uint64
+	}{
+		{"full write", 0, nil, false, 0},
+		{"short write", 3, io.ErrShortWrite, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConn{limit: tt.limit}
+			w := newReconnectWriter(func() (io.WriteCloser, error) { return conn, nil })
+
+			start := time.Now()
+			_, err := w.Write([]byte("record\n"))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Write error = %v, want %v", err, tt.wantErr)
+			}
+			if conn.deadline.Before(start.Add(sinkWriteTimeout)) {
+				t.Errorf("write deadline = %v, want at least %v after the write", conn.deadline, sinkWriteTimeout)
+			}
+			if conn.closed != tt.wantClosed {
+				t.Errorf("connection closed = %v, want %v", conn.closed, tt.wantClosed)
+			}
+			if got := w.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped = %d, want %d", got, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestReconnectWriterRedialsAfterShortWrite(t *testing.T) {
+	first := &fakeConn{limit: 3}
+	second := &fakeConn{}
+	conns := []*fakeConn{first, second}
+	w := newReconnectWriter(func() (io.WriteCloser, error) {
+		conn := conns[0]
+		conns = conns[1:]
+		return conn, nil
+	})
+
+	w.Write([]byte("first\n"))
+	if _, err := w.Write([]byte("dropped\n")); !errors.Is(err, errSinkUnavailable) {
+		t.Fatalf("Write during backoff error = %v, want %v", err, errSinkUnavailable)
+	}
+
+	// Skip the backoff
+	w.nextDial = time.Time{}
+	if _, err := w.Write([]byte("second\n")); err != nil {
+		t.Fatalf("Write after backoff: %v", err)
+	}
+	if got := second.buf.String(); got != "second\n" {
+		t.Errorf("new connection received %q, want a complete record", got)
+	}
+}
+
+func TestSetFraming(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing Framing
+	}{
+		{"newline", NewlineFraming},
+		{"length prefixed", LengthPrefixed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConn{}
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetFraming(tt.framing))
+			l.sink = newReconnectWriter(func() (io.WriteCloser, error) { return conn, nil })
+
+			// The params make the first record span several lines
+			l.Info("first", SetLogParams(map[string]interface{}{"port": 8080}))
+			l.Info("second")
+
+			var records []string
+			data := conn.buf.Bytes()
+			if tt.framing == NewlineFraming {
+				records = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			} else {
+				for len(data) >= 4 {
+					size := binary.BigEndian.Uint32(data)
+					records = append(records, string(data[4:4+size]))
+					data = data[4+size:]
+				}
+			}
+
+			wantRecords := 3
+			if tt.framing == LengthPrefixed {
+				wantRecords = 2
+			}
+			if len(records) != wantRecords {
+				t.Fatalf("deframed %d records, want %d: %q", len(records), wantRecords, records)
+			}
+			if tt.framing == LengthPrefixed && (!strings.Contains(records[0], "INFO: first\n") ||
+				!strings.Contains(records[0], `"port": 8080`) || !strings.HasSuffix(records[1], "INFO: second")) {
+				t.Errorf("records = %q, want one per message", records)
+			}
+		})
+	}
+}
+
+func TestSetFramingRejectsUnknownFraming(t *testing.T) {
+	if _, err := NewLogger(SetFraming(Framing(7))); err == nil {
+		t.Error("NewLogger accepted an unknown framing")
+	}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package asynclog
+
+import (
+	"io"
+	"net"
+)
+
+// SetUnixSocketSink sends formatted file lines to a Unix domain socket,
+// e.g. one served by a local log-forwarding agent. The socket is dialed lazily
+// and redialed with backoff after failures; lines written while it is
+// unavailable are dropped and counted by SinkDropped.
+func SetUnixSocketSink(path string) LoggerOption {
+	return func(l *Logger) error {
+		l.sink = newReconnectWriter(func() (io.WriteCloser, error) {
+			return net.Dial("unix", path)
+		})
+		return nil
+	}
+}
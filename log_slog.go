@@ -0,0 +1,162 @@
+package asynclog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHandler is a slog.Handler writing records through a Logger.
+type slogHandler struct {
+	logger *Logger                // Logger receiving the records.
+	attrs  map[string]interface{} // Params bound with WithAttrs, nested by group.
+	groups []string               // Groups opened with WithGroup, outermost first.
+}
+
+// NewSlogHandler returns a slog.Handler that writes records through the logger,
+// so it can be used as the backend of log/slog:
+//
+//	slog.SetDefault(slog.New(asynclog.NewSlogHandler(logger)))
+//
+// Record attributes become params, and groups become nested params.
+// Source info is taken from the record when the logger adds source info,
+// and params are extracted from the context as set with SetContextExtractor.
+func NewSlogHandler(l *Logger) slog.Handler {
+	return &slogHandler{logger: l}
+}
+
+// slogLevel maps a slog level to the nearest log level at or below it.
+// Levels above slog.LevelError, such as LevelError+4, map to Fatal.
+func slogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelDebug:
+		return LogLevelTrace
+	case level < slog.LevelInfo:
+		return LogLevelDebug
+	case level < slog.LevelWarn:
+		return LogLevelInfo
+	case level < slog.LevelError:
+		return LogLevelWarning
+	case level < slog.LevelError+4:
+		return LogLevelError
+	default:
+		return LogLevelFatal
+	}
+}
+
+// Enabled reports whether records of the given level are logged.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(slogLevel(level))
+}
+
+// Handle logs a record.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	params := copyParams(h.attrs)
+	r.Attrs(func(attr slog.Attr) bool {
+		params = addSlogAttrs(params, h.groups, []slog.Attr{attr})
+		return true
+	})
+
+	h.logger.log(slogLevel(r.Level), r.Message, contextParams(ctx), func(m *LogMessage) {
+		for key, value := range params {
+			m.setParam(key, value)
+		}
+		if !r.Time.IsZero() {
+			m.Time = r.Time
+		}
+		m.pc = r.PC
+	})
+	return nil
+}
+
+// WithAttrs returns a handler that adds the attributes to every record.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return &slogHandler{
+		logger: h.logger,
+		attrs:  addSlogAttrs(copyParams(h.attrs), h.groups, attrs),
+		groups: h.groups,
+	}
+}
+
+// WithGroup returns a handler that nests the attributes added later under the group.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{
+		logger: h.logger,
+		attrs:  h.attrs,
+		groups: append(h.groups[:len(h.groups):len(h.groups)], name),
+	}
+}
+
+// addSlogAttrs adds attributes to params under the given groups and returns params.
+// Groups are only created once they hold an attribute, as log/slog requires.
+func addSlogAttrs(params map[string]interface{}, groups []string, attrs []slog.Attr) map[string]interface{} {
+	target := map[string]interface{}{}
+	for _, attr := range attrs {
+		setSlogAttr(target, attr)
+	}
+	if len(target) == 0 {
+		return params
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	// Walk down to the innermost group, creating the groups on the way
+	parent := params
+	for _, group := range groups {
+		nested, ok := parent[group].(paramGroup)
+		if !ok {
+			nested = paramGroup{}
+			parent[group] = nested
+		}
+		parent = nested
+	}
+	for key, value := range target {
+		parent[key] = value
+	}
+	return params
+}
+
+// setSlogAttr stores a resolved attribute in params. Group attributes become
+// nested params, or are inlined when their key is empty.
+func setSlogAttr(params map[string]interface{}, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		params[attr.Key] = attr.Value.Any()
+		return
+	}
+
+	group := params
+	if attr.Key != "" {
+		group = paramGroup{}
+	}
+	for _, member := range attr.Value.Group() {
+		setSlogAttr(group, member)
+	}
+	if attr.Key != "" && len(group) > 0 {
+		params[attr.Key] = paramGroup(group)
+	}
+}
+
+// copyParams returns a deep copy of params, copying nested groups.
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		if nested, ok := value.(paramGroup); ok {
+			value = paramGroup(copyParams(nested))
+		}
+		copied[key] = value
+	}
+	return copied
+}
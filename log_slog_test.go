@@ -0,0 +1,100 @@
+package asynclog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func(*slog.Logger)
+		want   string
+		absent string
+	}{
+		{"record attrs", func(s *slog.Logger) {
+			s.Info("request", "method", "GET", "status", 200)
+		}, "INFO: request\nmethod=GET status=200", ""},
+		{"bound attrs merge", func(s *slog.Logger) {
+			s.With("service", "api").Info("request", "user", "bob")
+		}, "INFO: request\nservice=api user=bob", ""},
+		{"record attr overrides bound attr", func(s *slog.Logger) {
+			s.With("user", "alice").Info("request", "user", "bob")
+		}, "INFO: request\nuser=bob", "alice"},
+		{"group prefix", func(s *slog.Logger) {
+			s.WithGroup("http").Info("request", "method", "GET")
+		}, "INFO: request\nhttp.method=GET", ""},
+		{"attrs around a group", func(s *slog.Logger) {
+			s.With("service", "api").WithGroup("http").With("status", 200).Info("request", "method", "GET")
+		}, "INFO: request\nhttp.method=GET http.status=200 service=api", ""},
+		{"nested groups", func(s *slog.Logger) {
+			s.WithGroup("a").WithGroup("b").Info("request", "c", 1)
+		}, "INFO: request\na.b.c=1", ""},
+		{"group attr", func(s *slog.Logger) {
+			s.Info("query", slog.Group("db", "rows", 3, "table", "users"))
+		}, "INFO: query\ndb.rows=3 db.table=users", ""},
+		{"inline group attr", func(s *slog.Logger) {
+			s.Info("query", slog.Group("", "rows", 3))
+		}, "INFO: query\nrows=3", ""},
+		{"empty group omitted", func(s *slog.Logger) {
+			s.WithGroup("empty").Info("idle")
+		}, "INFO: idle\n", "empty"},
+		{"warn level", func(s *slog.Logger) {
+			s.Warn("slow")
+		}, "WARNING: slow\n", ""},
+		{"error level", func(s *slog.Logger) {
+			s.Error("failed")
+		}, "ERROR: failed\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetFileWriter(&buf),
+				SetFileLevel(LogLevelDebug), SetParamFormatter(FormatParamsAsLogfmt))
+
+			tt.log(slog.New(NewSlogHandler(l)))
+
+			got := buf.String()
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("output = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.absent != "" && strings.Contains(got, tt.absent) {
+				t.Errorf("output = %q, want it without %q", got, tt.absent)
+			}
+		})
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  LogLevel
+	}{
+		{slog.LevelDebug - 4, LogLevelTrace},
+		{slog.LevelDebug, LogLevelDebug},
+		{slog.LevelInfo, LogLevelInfo},
+		{slog.LevelInfo + 2, LogLevelInfo},
+		{slog.LevelWarn, LogLevelWarning},
+		{slog.LevelError, LogLevelError},
+		{slog.LevelError + 4, LogLevelFatal},
+	}
+	for _, tt := range tests {
+		if got := slogLevel(tt.level); got != tt.want {
+			t.Errorf("slogLevel(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandlerEnabled(t *testing.T) {
+	l, _ := newTestLogger(t, SetFileLevel(LogLevelWarning), SetConsoleLevel(LogLevelWarning))
+	s := slog.New(NewSlogHandler(l))
+	if s.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Info enabled below the logger levels")
+	}
+	if !s.Enabled(context.Background(), slog.LevelError) {
+		t.Error("Error disabled above the logger levels")
+	}
+}
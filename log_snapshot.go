@@ -0,0 +1,67 @@
+package asynclog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SetSnapshotFile makes the named log file a snapshot file: instead of being
+// appended to, it is replaced by each new record, e.g. for a status or heartbeat
+// file read by monitoring. The record is written to a temporary file in the same
+// directory and renamed over the target, so readers always see a complete file.
+// When several records are written at once, the file keeps the last one.
+func SetSnapshotFile(fileName string) LoggerOption {
+	return func(l *Logger) error {
+		if fileName == "" {
+			return fmt.Errorf("snapshot file name must not be empty")
+		}
+		if l.snapshotFiles == nil {
+			l.snapshotFiles = make(map[string]bool)
+		}
+		l.snapshotFiles[filepath.Clean(fileName)] = true
+		return nil
+	}
+}
+
+// isSnapshotFile reports whether a file is a snapshot file. Names are compared
+// cleaned, so "./state.log" and "state.log" refer to the same file.
+func (l *Logger) isSnapshotFile(filename string) bool {
+	return l.snapshotFiles != nil && l.snapshotFiles[filepath.Clean(filename)]
+}
+
+// writeSnapshot atomically replaces the file with the record.
+// The caller must hold fileMutex.
+func (l *Logger) writeSnapshot(filename, record string) error {
+	if err := l.createDir(filename); err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	// Remove the temporary file unless it has been renamed
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.WriteString(record); err != nil {
+		temp.Close()
+		return fmt.Errorf("error writing to snapshot file: %w", err)
+	}
+	// Sync before the rename so the new file is complete after a crash
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to flush snapshot file: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to close snapshot file: %w", err)
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set snapshot file mode: %w", err)
+	}
+
+	// os.Rename replaces an existing target on every platform, including Windows
+	if err := os.Rename(temp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to replace snapshot file: %w", err)
+	}
+	return nil
+}
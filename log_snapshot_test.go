@@ -0,0 +1,53 @@
+package asynclog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotFile(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.log")
+	sep := string(filepath.Separator)
+	tests := []struct {
+		name       string
+		registered string
+		logged     string
+	}{
+		{"same name", state, state},
+		{"registered with dot", dir + sep + "." + sep + "state.log", state},
+		{"logged with dot", state, dir + sep + "." + sep + "state.log"},
+		{"registered with dot-dot", dir + sep + "sub" + sep + ".." + sep + "state.log", state},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(state)
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetFileNameSanitizer(acceptFileName),
+				SetSnapshotFile(tt.registered))
+
+			l.Info("status: starting", SetLogFile(tt.logged))
+			l.Info("status: ready", SetLogFile(tt.logged))
+
+			got := readFile(t, state)
+			if strings.Count(got, "\n") != 1 || !strings.HasSuffix(got, "INFO: status: ready\n") {
+				t.Errorf("snapshot file = %q, want only the last record", got)
+			}
+		})
+	}
+}
+
+func TestSnapshotFileKeepsOtherFilesAppending(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetSnapshotFile(filepath.Join(t.TempDir(), "state.log")))
+
+	l.Info("first")
+	l.Info("second")
+
+	if got := readFile(t, file); strings.Count(got, "\n") != 2 {
+		t.Errorf("file = %q, want both records appended", got)
+	}
+	if _, err := NewLogger(SetSnapshotFile("")); err == nil {
+		t.Error("NewLogger accepted an empty snapshot file name")
+	}
+}
@@ -0,0 +1,67 @@
+package asynclog
+
+import "time"
+
+// ElapsedParamKey is the param key holding the elapsed milliseconds of a span.
+const ElapsedParamKey = "elapsed_ms"
+
+// Span marks the start of a timed operation, as returned by Logger.Start.
+type Span struct {
+	start time.Time        // Time the span was started.
+	now   func() time.Time // Clock of the logger that started the span.
+}
+
+// Start returns a span marking the current time of the logger's clock, to be
+// passed to one of the *Since methods, e.g. logger.InfoSince(span, "request handled").
+func (l *Logger) Start() Span {
+	root := l.root()
+	return Span{start: root.now(), now: root.now}
+}
+
+// Elapsed returns the time passed since the span was started, according to
+// the clock of the logger that started it.
+func (s Span) Elapsed() time.Duration {
+	if s.now == nil {
+		return time.Since(s.start)
+	}
+	return s.now().Sub(s.start)
+}
+
+// elapsedOption returns an option adding the span's elapsed milliseconds as a param.
+func (s Span) elapsedOption() LogOption {
+	return func(m *LogMessage) {
+		m.setParam(ElapsedParamKey, float64(s.Elapsed())/float64(time.Millisecond))
+	}
+}
+
+// TraceSince logs a message at the Trace level with the milliseconds elapsed since the span started.
+func (l *Logger) TraceSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelTrace, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+}
+
+// DebugSince logs a message at the Debug level with the milliseconds elapsed since the span started.
+func (l *Logger) DebugSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelDebug, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+}
+
+// InfoSince logs a message at the Info level with the milliseconds elapsed since the span started.
+func (l *Logger) InfoSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelInfo, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+}
+
+// WarningSince logs a message at the Warning level with the milliseconds elapsed since the span started.
+func (l *Logger) WarningSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelWarning, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+}
+
+// ErrorSince logs a message at the Error level with the milliseconds elapsed since the span started.
+func (l *Logger) ErrorSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelError, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+}
+
+// FatalSince logs a message at the Fatal level with the milliseconds elapsed since the span started,
+// and exits the process like Fatal.
+func (l *Logger) FatalSince(span Span, message string, opts ...LogOption) {
+	l.log(LogLevelFatal, message, append([]LogOption{span.elapsedOption()}, opts...)...)
+	l.exitFatal()
+}
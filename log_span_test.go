@@ -0,0 +1,36 @@
+package asynclog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpanUsesLoggerClock(t *testing.T) {
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    string
+	}{
+		{"zero", 0, `"elapsed_ms": 0`},
+		{"sub-millisecond", 250 * time.Microsecond, `"elapsed_ms": 0.25`},
+		{"seconds", 1500 * time.Millisecond, `"elapsed_ms": 1500`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+			l, file := newTestLogger(t, SetMode(Synchronous), SetClock(func() time.Time { return now }))
+
+			span := l.Start()
+			now = now.Add(tt.elapsed)
+			if got := span.Elapsed(); got != tt.elapsed {
+				t.Errorf("Elapsed = %v, want %v", got, tt.elapsed)
+			}
+
+			l.InfoSince(span, "done")
+			if got := readFile(t, file); !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
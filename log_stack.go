@@ -0,0 +1,72 @@
+package asynclog
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// StackKey is the param key of stack traces.
+const StackKey = "stack"
+
+// StackFrame is a single function call of a stack trace.
+type StackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Stack is a stack trace, innermost call first. JSON formatters render it as
+// an array of {func, file, line} objects, so backends can index single frames,
+// while text formatters print it like a Go stack trace, one frame per two lines.
+type Stack []StackFrame
+
+// String formats the stack trace in the multi-line layout of Go stack traces.
+func (s Stack) String() string {
+	var builder strings.Builder
+	for i, frame := range s {
+		if i > 0 {
+			builder.WriteByte('\n')
+		}
+		builder.WriteString(frame.Func + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line))
+	}
+	return builder.String()
+}
+
+// CaptureStack returns the stack trace of the calling goroutine. The argument
+// skip is the number of frames to skip, with 0 identifying the caller of CaptureStack.
+func CaptureStack(skip int) Stack {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack Stack
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, StackFrame{Func: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// packagePrefix is the prefix of the names of functions in this package.
+var packagePrefix = func() string {
+	name := runtime.FuncForPC(reflect.ValueOf(CaptureStack).Pointer()).Name()
+	return name[:strings.LastIndex(name, ".")+1]
+}()
+
+// SetStackTrace attaches the stack trace of the log call to a message
+// under the "stack" param, starting at the function that called the logger.
+func SetStackTrace() LogOption {
+	return func(m *LogMessage) {
+		stack := CaptureStack(0)
+		// Skip the frames of the logger itself
+		for len(stack) > 0 && strings.HasPrefix(stack[0].Func, packagePrefix) {
+			stack = stack[1:]
+		}
+		m.setParam(StackKey, stack)
+	}
+}
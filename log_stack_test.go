@@ -0,0 +1,76 @@
+package asynclog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCaptureStack(t *testing.T) {
+	stack := CaptureStack(0)
+	line := thisLine() - 1
+
+	if len(stack) == 0 {
+		t.Fatal("empty stack")
+	}
+	top := stack[0]
+	if !strings.HasSuffix(top.Func, ".TestCaptureStack") || !strings.HasSuffix(top.File, "log_stack_test.go") || top.Line != line {
+		t.Errorf("top frame = %+v, want TestCaptureStack at line %d", top, line)
+	}
+}
+
+func TestStackString(t *testing.T) {
+	stack := Stack{{Func: "main.run", File: "/app/main.go", Line: 12}, {Func: "main.main", File: "/app/main.go", Line: 5}}
+	want := "main.run\n\t/app/main.go:12\nmain.main\n\t/app/main.go:5"
+	if got := stack.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSetStackTrace(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []LoggerOption
+	}{
+		{"text", nil},
+		{"json", []LoggerOption{SetStructuredJSON(true)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, append([]LoggerOption{SetMode(Synchronous)}, tt.opts...)...)
+
+			// The test is part of the package, so its own frames are skipped
+			// along with the logger's and the stack starts in the testing package
+			l.Error("failed", SetStackTrace())
+			got := readFile(t, file)
+
+			if tt.opts == nil {
+				// Text formatters print the stack one frame per two lines
+				if !strings.Contains(got, `"stack": testing.tRunner`+"\n\t") {
+					t.Errorf("file = %q, want a multi-line stack", got)
+				}
+				if strings.Contains(got, packagePrefix) {
+					t.Errorf("file = %q, want the frames of the logger skipped", got)
+				}
+				return
+			}
+			var record struct {
+				Stack []StackFrame `json:"stack"`
+			}
+			if err := json.Unmarshal([]byte(got), &record); err != nil {
+				t.Fatalf("line %q is not valid JSON: %v", got, err)
+			}
+			if len(record.Stack) == 0 {
+				t.Fatalf("stack = %q, want an array of frames", got)
+			}
+			for _, frame := range record.Stack {
+				if frame.Func == "" || frame.File == "" || frame.Line == 0 {
+					t.Errorf("frame = %+v, want func, file and line", frame)
+				}
+				if strings.HasPrefix(frame.Func, packagePrefix) {
+					t.Errorf("frame = %+v, want the frames of the logger skipped", frame)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package asynclog
+
+// Stats is a snapshot of the logger's internal counters.
+type Stats struct {
+	WriteErrors   uint64   // Number of failed file opens and writes.
+	SinkDropped   uint64   // Messages dropped because the sink was unavailable.
+	DegradedFiles []string // Files skipped after repeated failures, until their cooldown passes.
+	DegradedDrops uint64   // Messages skipped because their file was degraded.
+	Dropped       uint64   // Messages dropped because the LogChannel was full.
+	Sampled       uint64   // Messages dropped by SetSampling.
+}
+
+// Stats returns a snapshot of the logger's internal counters.
+func (l *Logger) Stats() Stats {
+	root := l.root()
+	return Stats{
+		WriteErrors:   root.WriteErrorCount(),
+		SinkDropped:   root.SinkDropped(),
+		DegradedFiles: root.degradedFiles(),
+		DegradedDrops: root.degradedDrops.Load(),
+		Dropped:       root.dropped.Load(),
+		Sampled:       root.sampled.Load(),
+	}
+}
@@ -0,0 +1,114 @@
+package asynclog
+
+import (
+	"io"
+	"sync"
+)
+
+// DefaultTailBuffer is the number of writes buffered for each Tail reader.
+const DefaultTailBuffer = 256
+
+// tailReader streams the data written to one log file, like tail -f.
+type tailReader struct {
+	logger *Logger
+	file   string
+	chunks chan []byte   // Writes waiting to be read, bounded by DefaultTailBuffer.
+	done   chan struct{} // Closed when the reader or the logger is closed.
+	once   sync.Once
+	buf    []byte // Unread rest of the current write.
+}
+
+// Tail returns a reader streaming the records written to the given log file
+// from now on, such as for a live log viewer. An empty name follows the default file.
+// The name is sanitized like those passed to SetLogFile, so "./jobs.log" follows
+// "jobs.log", and a rejected name follows the default file its messages go to.
+// Writes are teed to the reader as the logger writes them, so no file polling
+// or locking is involved. A reader that falls behind by more than
+// DefaultTailBuffer writes misses the newer ones rather than blocking the logger.
+// Read returns io.EOF once the reader or the logger is closed.
+func (l *Logger) Tail(file string) io.ReadCloser {
+	root := l.root()
+	root.settingsMutex.RLock()
+	file = root.resolveFileName(file)
+	root.settingsMutex.RUnlock()
+
+	r := &tailReader{
+		logger: root,
+		file:   file,
+		chunks: make(chan []byte, DefaultTailBuffer),
+		done:   make(chan struct{}),
+	}
+
+	root.tailMutex.Lock()
+	if root.tails == nil {
+		root.tails = make(map[string][]*tailReader)
+	}
+	root.tails[file] = append(root.tails[file], r)
+	root.tailMutex.Unlock()
+	return r
+}
+
+// publishTail passes data written to a file on to its Tail readers.
+func (l *Logger) publishTail(file, data string) {
+	l.tailMutex.Lock()
+	defer l.tailMutex.Unlock()
+
+	for _, r := range l.tails[file] {
+		select {
+		case r.chunks <- []byte(data):
+		default:
+			// Drop the write for a reader that keeps falling behind
+		}
+	}
+}
+
+// closeTails ends every Tail reader, e.g. when the logger is closed.
+func (l *Logger) closeTails() {
+	l.tailMutex.Lock()
+	readers := l.tails
+	l.tails = nil
+	l.tailMutex.Unlock()
+
+	for _, list := range readers {
+		for _, r := range list {
+			r.once.Do(func() { close(r.done) })
+		}
+	}
+}
+
+// Read reads the data written to the file since the previous read,
+// blocking until there is some.
+func (r *tailReader) Read(p []byte) (int, error) {
+	if len(r.buf) == 0 {
+		select {
+		case chunk := <-r.chunks:
+			r.buf = chunk
+		case <-r.done:
+			return 0, io.EOF
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close stops the reader and unsubscribes it from the logger.
+func (r *tailReader) Close() error {
+	r.once.Do(func() { close(r.done) })
+
+	l := r.logger
+	l.tailMutex.Lock()
+	defer l.tailMutex.Unlock()
+
+	list := l.tails[r.file]
+	for i, other := range list {
+		if other == r {
+			l.tails[r.file] = append(list[:i:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(l.tails[r.file]) == 0 {
+		delete(l.tails, r.file)
+	}
+	return nil
+}
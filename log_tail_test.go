@@ -0,0 +1,65 @@
+package asynclog
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTail(t *testing.T) {
+	tests := []struct {
+		name    string
+		tail    string
+		logFile string
+	}{
+		{"default file", "", ""},
+		{"same name", "jobs.log", "jobs.log"},
+		{"tail name not clean", "./jobs.log", "jobs.log"},
+		{"log name not clean", "jobs.log", "./jobs.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			sanitizer := func(name string) (string, error) {
+				return filepath.Join(dir, filepath.Clean(name)), nil
+			}
+			l, _ := newTestLogger(t, SetFileNameSanitizer(sanitizer))
+
+			r := l.Tail(tt.tail)
+			defer r.Close()
+			var opts []LogOption
+			if tt.logFile != "" {
+				opts = append(opts, SetLogFile(tt.logFile))
+			}
+			l.Info("followed", opts...)
+
+			got := make(chan string, 1)
+			go func() {
+				buf := make([]byte, 256)
+				n, _ := r.Read(buf)
+				got <- string(buf[:n])
+			}()
+			select {
+			case line := <-got:
+				if !strings.Contains(line, "INFO: followed") {
+					t.Errorf("Tail read %q, want the logged line", line)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("Tail received nothing")
+			}
+		})
+	}
+}
+
+func TestTailEndsOnClose(t *testing.T) {
+	l, _ := newTestLogger(t)
+	r := l.Tail("")
+
+	l.Close()
+
+	if _, err := r.Read(make([]byte, 16)); err != io.EOF {
+		t.Errorf("Read after Close error = %v, want io.EOF", err)
+	}
+}
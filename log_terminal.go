@@ -0,0 +1,106 @@
+package asynclog
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ellipsis marks console lines truncated to the terminal width.
+const ellipsis = "…"
+
+// SetConsoleTruncate enables or disables truncating console lines to the terminal width.
+// Truncated lines end with an ellipsis; file output always keeps the full lines.
+// The width is refreshed when the terminal is resized, where the platform
+// reports it. Truncation is off by default and has no effect when the console
+// is not a terminal.
+func SetConsoleTruncate(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.truncateConsole = enable
+		return nil
+	}
+}
+
+// startWidthWatcher records the terminal width and keeps it up to date on resize.
+func (l *Logger) startWidthWatcher() {
+	l.updateConsoleWidth()
+	l.stopResizeWatch = watchTerminalResize(l.updateConsoleWidth)
+}
+
+// updateConsoleWidth stores the current terminal width, or 0 if it is unknown.
+func (l *Logger) updateConsoleWidth() {
+	width, _, err := term.GetSize(int(l.consoleFile().Fd()))
+	if err != nil {
+		width = 0
+	}
+	l.consoleWidth.Store(int64(width))
+}
+
+// truncateConsoleMessage truncates every line of a console message to the terminal width.
+func (l *Logger) truncateConsoleMessage(message string) string {
+	width := int(l.consoleWidth.Load())
+	if width <= 0 {
+		return message
+	}
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = truncateLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncateLine truncates a line to width visible characters, ending it with an ellipsis.
+// ANSI escape sequences do not count towards the width and are kept intact,
+// and colors are reset after a truncated line.
+func truncateLine(line string, width int) string {
+	var builder strings.Builder
+	visible := 0
+	colored := false
+
+	for i := 0; i < len(line); {
+		// Copy escape sequences such as "\x1b[31m" without counting them
+		if line[i] == '\x1b' && i+1 < len(line) && line[i+1] == '[' {
+			end := i + 2
+			for end < len(line) && (line[end] < 0x40 || line[end] > 0x7e) {
+				end++
+			}
+			if end < len(line) {
+				end++
+			}
+			builder.WriteString(line[i:end])
+			colored = true
+			i = end
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(line[i:])
+		if visible == width-1 && hasVisibleRunes(line[i+size:]) {
+			builder.WriteString(ellipsis)
+			if colored {
+				builder.WriteString("\x1b[0m")
+			}
+			return builder.String()
+		}
+		builder.WriteRune(r)
+		visible++
+		i += size
+	}
+	return builder.String()
+}
+
+// hasVisibleRunes reports whether text contains anything besides ANSI escape sequences.
+func hasVisibleRunes(text string) bool {
+	for i := 0; i < len(text); {
+		if text[i] == '\x1b' && i+1 < len(text) && text[i+1] == '[' {
+			i += 2
+			for i < len(text) && (text[i] < 0x40 || text[i] > 0x7e) {
+				i++
+			}
+			i++
+			continue
+		}
+		return true
+	}
+	return false
+}
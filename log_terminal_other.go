@@ -0,0 +1,9 @@
+//go:build !unix
+
+package asynclog
+
+// watchTerminalResize is a no-op on platforms without SIGWINCH;
+// the terminal width is only read when the logger is created.
+func watchTerminalResize(update func()) func() {
+	return func() {}
+}
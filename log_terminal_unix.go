@@ -0,0 +1,33 @@
+//go:build unix
+
+package asynclog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// watchTerminalResize calls update whenever the terminal is resized (SIGWINCH).
+// It returns a function that stops watching.
+func watchTerminalResize(update func()) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				update()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
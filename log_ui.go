@@ -0,0 +1,24 @@
+package asynclog
+
+// SetUICallback sets a function receiving every processed message as an
+// uncolored line, e.g. to show logs in a widget of a desktop application.
+// The line is the file record, or the default text layout for messages not
+// written to files or written in the binary format.
+// The callback runs on the log processing goroutine (or the caller's goroutine
+// in synchronous mode), so GUI code must hand the line over to its UI thread
+// and return quickly; a slow callback delays all logging.
+func SetUICallback(callback func(level LogLevel, line string)) LoggerOption {
+	return func(l *Logger) error {
+		l.uiCallback = callback
+		return nil
+	}
+}
+
+// runUICallback passes a message to the UI callback.
+func (l *Logger) runUICallback(logMessage LogMessage) {
+	line := logMessage.FileMessage
+	if line == "" || l.fileFormat == FormatBinary {
+		line = FormatLineAsText(logMessage)
+	}
+	l.uiCallback(logMessage.Level, line)
+}
@@ -0,0 +1,45 @@
+package asynclog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetUICallback(t *testing.T) {
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	type delivery struct {
+		level LogLevel
+		line  string
+	}
+	var got []delivery
+	var console bytes.Buffer
+	l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true), SetClock(func() time.Time { return at }),
+		EnableConsoleOutput(true), SetConsoleWriter(&console), SetColorEnabled(true),
+		SetUICallback(func(level LogLevel, line string) { got = append(got, delivery{level, line}) }))
+
+	l.Error("failed", SetLogParams(map[string]interface{}{"code": 7}))
+	// Debug messages only reach the console, so the callback gets the text layout
+	l.Debug("details")
+
+	want := []delivery{
+		{LogLevelError, "[2024/06/01 12:00:00] ERROR: failed\n  \"code\": 7"},
+		{LogLevelDebug, "[2024/06/01 12:00:00] DEBUG: details"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("callback got %d lines, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("delivery %d = %v %q, want %v %q", i, got[i].level, got[i].line, want[i].level, want[i].line)
+		}
+	}
+	// The lines are the file records, not the colored console lines
+	if !strings.Contains(console.String(), "\x1b[") {
+		t.Errorf("console = %q, want colored lines", console.String())
+	}
+	if record := readFile(t, file); record != want[0].line+"\n" {
+		t.Errorf("file = %q, want the line passed to the callback", record)
+	}
+}
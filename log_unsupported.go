@@ -0,0 +1,92 @@
+package asynclog
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedValueHandler replaces a param value that formatters cannot render
+// meaningfully, such as a func, a channel or a complex number. It returns the
+// value to format instead, or false to leave the param out.
+type UnsupportedValueHandler func(key string, value interface{}) (interface{}, bool)
+
+// SetUnsupportedValueHandler sets the handler for param values that formatters
+// cannot render. By default, DefaultUnsupportedValueHandler is used.
+func SetUnsupportedValueHandler(handler UnsupportedValueHandler) LoggerOption {
+	return func(l *Logger) error {
+		if handler == nil {
+			return fmt.Errorf("unsupported value handler must not be nil")
+		}
+		l.valueHandler = handler
+		return nil
+	}
+}
+
+// DefaultUnsupportedValueHandler renders funcs, channels and unsafe pointers
+// as their type in angle brackets, e.g. "<func()>" or "<chan int>",
+// and complex numbers as strings such as "(1+2i)".
+func DefaultUnsupportedValueHandler(key string, value interface{}) (interface{}, bool) {
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(value), true
+	default:
+		return "<" + reflect.TypeOf(value).String() + ">", true
+	}
+}
+
+// isUnsupportedValue reports whether %v prints a meaningless address for the
+// value or JSON encoding fails on it.
+func isUnsupportedValue(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return true
+	default:
+		return false
+	}
+}
+
+// replaceUnsupportedValues returns the params with unsupported values passed
+// through the handler, including those in nested param maps.
+// The original map is left untouched.
+func replaceUnsupportedValues(params map[string]interface{}, handler UnsupportedValueHandler) map[string]interface{} {
+	replaced, _ := replaceUnsupported(params, handler)
+	return replaced
+}
+
+// replaceUnsupported is replaceUnsupportedValues, also reporting whether any value was replaced.
+func replaceUnsupported(params map[string]interface{}, handler UnsupportedValueHandler) (map[string]interface{}, bool) {
+	var replaced map[string]interface{}
+	for key, value := range params {
+		newValue, keep := value, true
+		if nested, ok := nestedParams(value); ok {
+			group, changed := replaceUnsupported(nested, handler)
+			if !changed {
+				continue
+			}
+			newValue = renest(value, group)
+		} else if isUnsupportedValue(value) {
+			newValue, keep = handler(key, value)
+		} else {
+			continue
+		}
+
+		if replaced == nil {
+			replaced = make(map[string]interface{}, len(params))
+			for k, v := range params {
+				replaced[k] = v
+			}
+		}
+		if keep {
+			replaced[key] = newValue
+		} else {
+			delete(replaced, key)
+		}
+	}
+	if replaced == nil {
+		return params, false
+	}
+	return replaced, true
+}
@@ -0,0 +1,92 @@
+package asynclog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUnsupportedValues(t *testing.T) {
+	params := map[string]interface{}{
+		"callback": func() {},
+		"events":   make(chan int),
+		"signal":   complex(1, 2),
+		"name":     "job",
+	}
+	tests := []struct {
+		name      string
+		formatter ParamFormatter
+		want      []string
+	}{
+		{"key-value", FormatParamsAsKeyValue, []string{`"callback": <func()>`, `"events": <chan int>`, `"signal": (1+2i)`, `"name": job`}},
+		{"json", FormatParamsAsJSON, []string{`"callback": "\u003cfunc()\u003e"`, `"events": "\u003cchan int\u003e"`, `"signal": "(1+2i)"`, `"name": "job"`}},
+		{"logfmt", FormatParamsAsLogfmt, []string{`callback=<func()>`, `events="<chan int>"`, `signal=(1+2i)`, `name=job`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetParamFormatter(tt.formatter))
+
+			l.Info("scheduled", SetLogParams(params))
+
+			got := readFile(t, file)
+			if strings.Contains(got, "Error formatting params") {
+				t.Fatalf("params block failed to format: %q", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("file = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedValueHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler UnsupportedValueHandler
+		want    string
+		absent  string
+	}{
+		{
+			name:    "replace",
+			handler: func(key string, value interface{}) (interface{}, bool) { return "redacted-" + key, true },
+			want:    `"callback": redacted-callback`,
+		},
+		{
+			name:    "drop",
+			handler: func(string, interface{}) (interface{}, bool) { return nil, false },
+			absent:  "callback",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUnsupportedValueHandler(tt.handler))
+
+			l.Info("scheduled", SetLogParams(map[string]interface{}{"callback": func() {}, "name": "job"}))
+
+			got := readFile(t, file)
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.absent != "" && strings.Contains(got, tt.absent) {
+				t.Errorf("file = %q, want no %q", got, tt.absent)
+			}
+			if !strings.Contains(got, `"name": job`) {
+				t.Errorf("file = %q, want the supported param kept", got)
+			}
+		})
+	}
+}
+
+func TestFormatParamsAsJSONUnsupportedValues(t *testing.T) {
+	out := FormatParamsAsJSON(map[string]interface{}{"callback": func() {}, "count": 2})
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatalf("FormatParamsAsJSON produced invalid JSON %q: %v", out, err)
+	}
+	if decoded["callback"] != "<func()>" || decoded["count"] != float64(2) {
+		t.Errorf("decoded %v", decoded)
+	}
+}
@@ -7,9 +7,19 @@ import (
 
 // getCallerInfo retrieves the filename and line number of the log caller.
 func getCallerInfo() (string, int) {
-	_, file, line, ok := runtime.Caller(3) // Adjust the stack frame to get the correct caller
+	_, file, line, ok := runtime.Caller(4) // Adjust the stack frame to get the correct caller
 	if !ok {
 		return "unknown", 0
 	}
 	return filepath.Base(file), line
 }
+
+// getFrameInfo retrieves the filename and line number of a program counter,
+// e.g. one recorded by log/slog.
+func getFrameInfo(pc uintptr) (string, int) {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return "unknown", 0
+	}
+	return filepath.Base(frame.File), frame.Line
+}
@@ -2,43 +2,258 @@ package asynclog
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
-// writeFile writes a message to the specified file.
+// writeFile writes one or more messages to the specified file with a single write.
 // It's responsible for opening and maintaining file handles,
 // as well as writing log messages to these files.
-func (l *Logger) writeFile(filename, message string) {
+func (l *Logger) writeFile(filename string, messages ...string) {
 	l.fileMutex.Lock()
 	defer l.fileMutex.Unlock()
 
+	// Hand the records to the file writer instead, if one is set
+	if l.fileWriter != nil {
+		l.writeFileWriter(filename, messages)
+		return
+	}
+
 	// Clean up file handles before opening a new file
 	// to ensure the total number does not exceed the maximum limit
 	if len(l.fileHandles) > l.maxFileHandles {
 		l.cleanupFileHandles()
 	}
 
-	// Ensure the file handle is present and open
-	file, ok := l.fileHandles[filename]
-	if !ok || file == nil {
-		var err error
-		file, err = os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			fmt.Printf("Failed to open log file: %v\n", err)
+	// Write to the file of the current day, keeping the name followers use
+	logical := filename
+	if l.dailyRotation && !l.isSnapshotFile(filename) {
+		filename = l.currentDatedFile(filename)
+	}
+
+	// Skip files that keep failing until their cooldown has passed
+	if l.isDegraded(filename) {
+		return
+	}
+
+	// Replace snapshot files instead of appending to them
+	if l.isSnapshotFile(filename) {
+		data := l.joinRecords(messages[len(messages)-1:])
+		if err := l.writeSnapshot(filename, data); err != nil {
+			l.fileFailed(filename, err)
 			return
 		}
-		l.fileHandles[filename] = file
+		l.fileSucceeded(filename)
+		l.publishTail(filename, data)
+		return
+	}
+
+	// Rotate the file before a write that would make it exceed the size limit,
+	// numbering the records again for the new file
+	data := l.fileRecords(filename, messages)
+	if l.maxFileSize > 0 && l.rotateIfFull(filename, int64(len(data))) {
+		data = l.fileRecords(filename, messages)
+	}
+
+	// Ensure the file handle is present and open. The handle of the previous
+	// write is reused without a lookup, which is the common single-file case.
+	file := l.lastHandle
+	if file == nil || l.lastFile != filename {
+		file = l.fileHandles[filename]
+		if file == nil {
+			var err error
+			if file, err = l.openFileLocked(filename); err != nil {
+				l.fileFailed(filename, err)
+				return
+			}
+		}
+		l.lastFile, l.lastHandle = filename, file
 	}
 
 	// Update the access time for the file handle
-	l.fileAccessTimes[filename] = time.Now()
+	l.fileAccessTimes[filename] = l.now()
 
 	// Write the log message to the file
-	if _, err := fmt.Fprintf(file, "%s\n", message); err != nil {
-		fmt.Printf("Error writing to log file: %v\n", err)
+	// Coordinate with other processes appending to the same file
+	if l.fileLocking {
+		if err := lockFile(file); err != nil {
+			l.recordWriteError(err)
+			l.diagf("Failed to lock log file: %w", err)
+		} else {
+			defer unlockFile(file)
+		}
+	}
+
+	err := l.writeData(filename, file, data)
+	if err == nil && l.fileLocking {
+		// Write buffered records out while the lock is held
+		err = l.flushBufferLocked(filename)
+	}
+	if err != nil {
+		l.fileFailed(filename, fmt.Errorf("error writing to log file: %w", err))
 		// Consider setting the file handle to nil on write failure
+		file.Close()
 		l.fileHandles[filename] = nil
+		delete(l.buffers, filename)
+		l.lastHandle = nil
+		return
+	}
+	l.fileSucceeded(filename)
+	l.publishTail(logical, data)
+	if l.writeManifest {
+		l.trackWriteLocked(filename)
+	}
+	if l.maxFileSize > 0 {
+		l.fileSizes[filename] += int64(len(data))
+	}
+
+	// Flush the file after every n messages
+	if l.flushEveryN > 0 {
+		l.flushCounts[filename] += len(messages)
+		if l.flushCounts[filename] >= l.flushEveryN {
+			l.flushFileLocked(filename)
+			l.flushCounts[filename] = 0
+		}
+	}
+}
+
+// openFileLocked opens a log file for appending and registers its handle.
+// The caller must hold fileMutex.
+func (l *Logger) openFileLocked(filename string) (*os.File, error) {
+	if err := l.createDir(filename); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(filename, l.openFlags(), 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+	l.fileHandles[filename] = file
+	if l.writeManifest {
+		l.trackOpenLocked(filename, file)
+	}
+
+	// Mark new files with the format version
+	if l.emitVersion && l.fileFormat == FormatText && !l.structuredJSON {
+		l.writeVersionHeader(file)
+	}
+
+	// Track the size of the file for rotation
+	if l.maxFileSize > 0 {
+		if info, err := file.Stat(); err == nil {
+			l.fileSizes[filename] = info.Size()
+		}
+	}
+	return file, nil
+}
+
+// createDir creates the missing parent directories of a file.
+func (l *Logger) createDir(filename string) error {
+	dir := filepath.Dir(filename)
+	if dir == "." {
+		return nil
+	}
+	return os.MkdirAll(dir, l.dirPerm)
+}
+
+// openFlags returns the flags log files are opened with.
+func (l *Logger) openFlags() int {
+	flags := os.O_APPEND | os.O_CREATE | os.O_WRONLY
+	if l.directSync {
+		flags |= os.O_SYNC
+	}
+	return flags
+}
+
+// fileRecords returns the records as they are written to a file, numbered
+// and framed as configured. Numbering advances the line count of the file.
+func (l *Logger) fileRecords(filename string, records []string) string {
+	if l.lineNumbers && l.fileFormat == FormatText {
+		records = l.numberRecords(filename, records)
+	}
+	if l.markRecords && l.fileFormat == FormatText {
+		records = l.frameRecords(records)
+	}
+	return l.joinRecords(records)
+}
+
+// joinRecords returns the records as they are written to a file:
+// newline-terminated text lines, or binary records as they are.
+func (l *Logger) joinRecords(records []string) string {
+	if l.fileFormat == FormatBinary {
+		return strings.Join(records, "")
+	}
+	return strings.Join(records, "\n") + "\n"
+}
+
+// flushFile flushes the written data of an open file to disk.
+func (l *Logger) flushFile(filename string) {
+	l.fileMutex.Lock()
+	defer l.fileMutex.Unlock()
+
+	l.flushFileLocked(filename)
+}
+
+// FlushFile flushes the data written to a single open log file to disk,
+// e.g. once a job writing to its own file has finished, without forcing disk
+// I/O on other files. An empty name refers to the default file. Messages still
+// queued are not waited for; call WaitIdle first to include them.
+// It returns an error if the file is not currently open.
+func (l *Logger) FlushFile(name string) error {
+	root := l.root()
+	filename := root.defaultFile()
+	if name != "" && name != filename {
+		var err error
+		if filename, err = root.sanitizeName(name); err != nil {
+			return err
+		}
+	}
+
+	root.fileMutex.Lock()
+	defer root.fileMutex.Unlock()
+
+	if dated, ok := root.datedFiles[filename]; ok {
+		filename = dated
+	}
+	if root.fileHandles[filename] == nil {
+		return fmt.Errorf("log file is not open: %s", filename)
+	}
+	return root.flushFileLocked(filename)
+}
+
+// flushFileLocked flushes an open file to disk. The caller must hold fileMutex.
+func (l *Logger) flushFileLocked(filename string) error {
+	file, ok := l.fileHandles[filename]
+	if !ok || file == nil {
+		return nil
+	}
+	if err := l.flushBufferLocked(filename); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		l.recordWriteError(err)
+		l.diagf("Failed to flush log file: %w", err)
+		return err
+	}
+	return nil
+}
+
+// formatVersionHeader returns the header line identifying the file format version.
+func formatVersionHeader() string {
+	return fmt.Sprintf("# asynclog format-version=%d", FormatVersion)
+}
+
+// writeVersionHeader writes the format version header if the file is empty.
+func (l *Logger) writeVersionHeader(file *os.File) {
+	info, err := file.Stat()
+	if err != nil || info.Size() > 0 {
+		return
+	}
+	if _, err := fmt.Fprintf(file, "%s\n", formatVersionHeader()); err != nil {
+		l.recordWriteError(err)
+		l.diagf("Error writing to log file: %w", err)
 	}
 }
 
@@ -46,46 +261,101 @@ func (l *Logger) writeFile(filename, message string) {
 // when the number of handles exceeds the maximum limit.
 func (l *Logger) cleanupFileHandles() {
 	for len(l.fileHandles) > l.maxFileHandles {
-		oldestTime := time.Now()
+		var oldestTime time.Time
 		oldestFile := ""
 
 		// Find the least recently used file handle
-		for filename, accessTime := range l.fileAccessTimes {
-			if accessTime.Before(oldestTime) {
+		for filename := range l.fileHandles {
+			accessTime := l.fileAccessTimes[filename]
+			if oldestFile == "" || accessTime.Before(oldestTime) {
 				oldestTime = accessTime
 				oldestFile = filename
 			}
 		}
 
 		// Close and remove the oldest file handle
-		if oldestFile != "" {
-			if file, ok := l.fileHandles[oldestFile]; ok {
-				if err := file.Close(); err != nil {
-					fmt.Printf("Failed to close log file: %v\n", err)
-				}
-				delete(l.fileHandles, oldestFile)
-				delete(l.fileAccessTimes, oldestFile)
+		l.closeFileLocked(oldestFile)
+	}
+}
+
+// cleanupLoop periodically closes unused file handles and removes old sidecar
+// files until the logger is closed.
+// Each interval is extended by a random jitter when one is configured.
+func (l *Logger) cleanupLoop() {
+	timer := time.NewTimer(l.jitteredInterval(DefaultCleanupTicker))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			l.cleanupUnusedFileHandles()
+			if l.sidecarSize > 0 {
+				l.cleanupSidecars()
 			}
+			timer.Reset(l.jitteredInterval(DefaultCleanupTicker))
+		case <-l.stop:
+			return
 		}
 	}
 }
 
+// jitteredInterval returns the interval plus a random delay of up to tickerJitter.
+func (l *Logger) jitteredInterval(interval time.Duration) time.Duration {
+	if l.tickerJitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Int63n(int64(l.tickerJitter)+1))
+}
+
 // cleanupUnusedFileHandles periodically closes file handles that have not been used for a certain duration.
 func (l *Logger) cleanupUnusedFileHandles() {
 	l.fileMutex.Lock()
 	defer l.fileMutex.Unlock()
 
-	threshold := time.Now().Add(-DefaultUnusedFileHandleThreshold)
+	threshold := l.now().Add(-DefaultUnusedFileHandleThreshold)
 
 	for filename, accessTime := range l.fileAccessTimes {
 		if accessTime.Before(threshold) {
-			if file, ok := l.fileHandles[filename]; ok {
-				if err := file.Close(); err != nil {
-					fmt.Printf("Failed to close log file: %v\n", err)
-				}
-				delete(l.fileHandles, filename)
-				delete(l.fileAccessTimes, filename)
+			if _, ok := l.fileHandles[filename]; ok {
+				l.closeFileLocked(filename)
 			}
 		}
 	}
 }
+
+// recordWriteError counts a failed file open or write and remembers the error.
+func (l *Logger) recordWriteError(err error) {
+	l.writeErrors.Add(1)
+	l.lastWriteError.Store(&err)
+}
+
+// WriteErrorCount returns the number of file open and write failures
+// since the logger was created or since the last call to ResetWriteErrors.
+func (l *Logger) WriteErrorCount() uint64 {
+	return l.root().writeErrors.Load()
+}
+
+// LastWriteError returns the most recent file open or write error,
+// or nil if none occurred since the logger was created or last reset.
+func (l *Logger) LastWriteError() error {
+	if err := l.root().lastWriteError.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// HealthyWrites reports whether no file writes have failed
+// since the logger was created or since the last call to ResetWriteErrors.
+// The counter is never reset automatically, so a single failure keeps
+// reporting unhealthy until the caller acknowledges it.
+func (l *Logger) HealthyWrites() bool {
+	return l.root().writeErrors.Load() == 0
+}
+
+// ResetWriteErrors clears the write error counter and the last write error,
+// e.g. after an operator has fixed a full disk or a permission problem.
+func (l *Logger) ResetWriteErrors() {
+	root := l.root()
+	root.writeErrors.Store(0)
+	root.lastWriteError.Store(nil)
+}
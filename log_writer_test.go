@@ -0,0 +1,208 @@
+package asynclog
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// acceptFileName is a file name sanitizer accepting absolute test paths.
+func acceptFileName(name string) (string, error) {
+	return name, nil
+}
+
+func TestLastHandleFastPath(t *testing.T) {
+	clock := func() time.Time { return time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name      string
+		alternate bool
+	}{
+		{"single file", false},
+		{"alternating files", true},
+	}
+	var outputs []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetClock(clock),
+				SetFileNameSanitizer(acceptFileName))
+			other := filepath.Join(filepath.Dir(file), "other.log")
+
+			// Alternating files defeats the reuse of the last handle,
+			// so every write looks its handle up
+			for i := 0; i < 10; i++ {
+				l.Info("message", SetLogParams(map[string]interface{}{"i": i}))
+				if tt.alternate {
+					l.Info("elsewhere", SetLogFile(other))
+				}
+			}
+			outputs = append(outputs, readFile(t, file))
+		})
+	}
+	if len(outputs) == 2 && outputs[0] != outputs[1] {
+		t.Errorf("outputs differ:\n%s\nwant:\n%s", outputs[1], outputs[0])
+	}
+}
+
+func BenchmarkLastHandleFastPath(b *testing.B) {
+	tests := []struct {
+		name      string
+		alternate bool
+	}{
+		{"single file", false},
+		{"alternating files", true},
+	}
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			l, file := newTestLogger(b, SetMode(Synchronous), SetFileNameSanitizer(acceptFileName))
+			files := []LogOption{SetLogFile(file), SetLogFile(file)}
+			if tt.alternate {
+				files[1] = SetLogFile(filepath.Join(filepath.Dir(file), "other.log"))
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				l.Info("request handled", files[i%2])
+			}
+		})
+	}
+}
+
+func TestSetDirectSync(t *testing.T) {
+	tests := []struct {
+		name   string
+		enable bool
+	}{
+		{"enabled", true},
+		{"disabled", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetDirectSync(tt.enable))
+			if got := l.openFlags()&os.O_SYNC != 0; got != tt.enable {
+				t.Errorf("O_SYNC in open flags = %v, want %v", got, tt.enable)
+			}
+
+			l.Info("durable")
+			if got := readFile(t, file); !strings.Contains(got, "INFO: durable") {
+				t.Errorf("file = %q, want the message written", got)
+			}
+		})
+	}
+}
+
+func TestNestedLogDirectories(t *testing.T) {
+	tests := []struct {
+		name string
+		perm os.FileMode
+		want os.FileMode
+	}{
+		{"default permission", 0, DefaultDirPerm},
+		{"custom permission", 0700, 0700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			file := filepath.Join(root, "logs", "app", "server.log")
+			opts := []LoggerOption{SetMode(Synchronous), SetDefaultFileName(file)}
+			if tt.perm != 0 {
+				opts = append(opts, SetDirPerm(tt.perm))
+			}
+			l, _ := newTestLogger(t, opts...)
+
+			l.Info("started")
+
+			if got := readFile(t, file); !strings.Contains(got, "INFO: started") {
+				t.Errorf("file = %q, want the message", got)
+			}
+			// The umask may clear bits, but never sets them
+			for _, dir := range []string{filepath.Join(root, "logs"), filepath.Join(root, "logs", "app")} {
+				info, err := os.Stat(dir)
+				if err != nil {
+					t.Fatalf("Stat: %v", err)
+				}
+				if !info.IsDir() || info.Mode().Perm()&^tt.want != 0 {
+					t.Errorf("%s has mode %v, want a directory within %v", dir, info.Mode(), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSetDirPermRejectsInvalidPermission(t *testing.T) {
+	if _, err := NewLogger(SetDirPerm(os.ModeDir | 0755)); err == nil {
+		t.Error("NewLogger accepted a permission with mode bits")
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	errDisk := errors.New("disk full")
+	tests := []struct {
+		name      string
+		writer    io.Writer
+		wantCount uint64
+		wantErr   error
+	}{
+		{"healthy", io.Discard, 0, nil},
+		{"failing writes", errWriter{errDisk}, 2, errDisk},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetMode(Synchronous), SetFileWriter(tt.writer), SetErrorHandler(func(error) {}))
+
+			l.Info("first")
+			l.Info("second")
+
+			if got := l.HealthyWrites(); got != (tt.wantCount == 0) {
+				t.Errorf("HealthyWrites = %v, want %v", got, tt.wantCount == 0)
+			}
+			if got := l.WriteErrorCount(); got != tt.wantCount {
+				t.Errorf("WriteErrorCount = %d, want %d", got, tt.wantCount)
+			}
+			if got := l.LastWriteError(); !errors.Is(got, tt.wantErr) {
+				t.Errorf("LastWriteError = %v, want %v", got, tt.wantErr)
+			}
+
+			// Counters are only cleared explicitly
+			l.ResetWriteErrors()
+			if !l.HealthyWrites() || l.WriteErrorCount() != 0 || l.LastWriteError() != nil {
+				t.Error("ResetWriteErrors did not clear the write errors")
+			}
+		})
+	}
+}
+
+func TestJitteredInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter time.Duration
+	}{
+		{"no jitter", 0},
+		{"jitter", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetTickerJitter(tt.jitter))
+			spread := make(map[time.Duration]bool)
+			for i := 0; i < 100; i++ {
+				got := l.jitteredInterval(DefaultCleanupTicker)
+				if got < DefaultCleanupTicker || got > DefaultCleanupTicker+tt.jitter {
+					t.Fatalf("jitteredInterval = %v, want between %v and %v", got, DefaultCleanupTicker, DefaultCleanupTicker+tt.jitter)
+				}
+				spread[got] = true
+			}
+			if tt.jitter > 0 && len(spread) < 2 {
+				t.Error("jitteredInterval returned the same interval every time")
+			}
+		})
+	}
+}
+
+func TestSetTickerJitterRejectsNegative(t *testing.T) {
+	if _, err := NewLogger(SetTickerJitter(-time.Second)); err == nil {
+		t.Error("NewLogger accepted a negative jitter")
+	}
+}
@@ -1,11 +1,17 @@
 package asynclog
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/fatih/color"
 )
 
 // LogLevel defines the severity of a log message.
@@ -19,6 +25,10 @@ const (
 	LogLevelError
 	LogLevelFatal
 
+	// LogLevelOff is above every other level. Using it as a FileLevel or
+	// ConsoleLevel disables that destination; messages are never logged at it.
+	LogLevelOff
+
 	// DefaultBufferSize is the default size of the log message channel.
 	DefaultBufferSize = 100
 
@@ -33,25 +43,209 @@ const (
 
 	// DefaultCleanupTicker is the interval for the cleanup routine.
 	DefaultCleanupTicker = 10 * time.Minute
+
+	// DefaultShutdownProgressInterval is the interval between Shutdown progress reports.
+	DefaultShutdownProgressInterval = time.Second
+
+	// DefaultDirPerm is the default permission of directories created for log files.
+	DefaultDirPerm os.FileMode = 0755
+
+	// DefaultTimeFormat is the default layout of log timestamps.
+	DefaultTimeFormat = "2006/01/02 15:04:05"
+
+	// FormatVersion is the version of the log file format written by this package.
+	// It is increased whenever the layout of written lines changes.
+	FormatVersion = 1
+)
+
+var (
+	// MinLevel is the lowest defined log level.
+	MinLevel = LogLevelTrace
+
+	// MaxLevel is the highest defined log level.
+	MaxLevel = LogLevelFatal
+)
+
+// AtLeast reports whether the level is as severe as or more severe than other.
+func (level LogLevel) AtLeast(other LogLevel) bool {
+	return level >= other
+}
+
+// Below reports whether the level is less severe than other.
+func (level LogLevel) Below(other LogLevel) bool {
+	return level < other
+}
+
+// Mode defines how log messages are dispatched to their destinations.
+type Mode int
+
+const (
+	// Asynchronous queues messages on LogChannel and writes them from a background goroutine.
+	Asynchronous Mode = iota
+
+	// Synchronous writes messages directly from the calling goroutine.
+	// No background goroutines are started, which makes output deterministic,
+	// but every log call pays the full cost of formatting and file I/O.
+	Synchronous
+)
+
+// Destination is a bit mask of the outputs a message may be written to.
+type Destination int
+
+const (
+	// FileOnly writes messages to file output only.
+	FileOnly Destination = 1 << iota
+
+	// ConsoleOnly writes messages to console output only.
+	ConsoleOnly
+
+	// FileAndConsole writes messages to both file and console output.
+	FileAndConsole = FileOnly | ConsoleOnly
+)
+
+// ConsoleStream selects the standard stream used for console output.
+type ConsoleStream int
+
+const (
+	// Stdout writes console output to the standard output.
+	Stdout ConsoleStream = iota
+
+	// Stderr writes console output to the standard error.
+	Stderr
 )
 
 // ParamFormatter is a function type for formatting log parameters.
 type ParamFormatter func(map[string]interface{}) string
 
+// LineFormatter is a function type for formatting a complete file log line.
+type LineFormatter func(LogMessage) string
+
 // Logger represents an asynchronous logger.
 type Logger struct {
-	LogChannel      chan LogMessage      // Channel for log messages.
-	FileLevel       LogLevel             // Minimum level of messages to log to file.
-	ConsoleLevel    LogLevel             // Minimum level of messages to log to console.
-	DefaultFileName string               // Default log file name.
-	OutputToFile    bool                 // Flag to enable or disable file output.
-	OutputToConsole bool                 // Flag to enable or disable console output.
-	paramFormatter  ParamFormatter       // Function used to format log parameters.
-	fileHandles     map[string]*os.File  // File handles for each log file.
-	fileAccessTimes map[string]time.Time // Last access time for each file handle.
-	fileMutex       sync.Mutex           // Mutex for synchronizing file access.
-	maxFileHandles  int                  // Maximum number of file handles.
-	AddSource       bool                 // Flag to add source file info in logs.
+	LogChannel      chan LogMessage                 // Channel for log messages.
+	FileLevel       LogLevel                        // Minimum level of messages to log to file.
+	ConsoleLevel    LogLevel                        // Minimum level of messages to log to console.
+	DefaultFileName string                          // Default log file name.
+	OutputToFile    bool                            // Flag to enable or disable file output.
+	OutputToConsole bool                            // Flag to enable or disable console output.
+	paramFormatter  ParamFormatter                  // Function used to format log parameters.
+	fileHandles     map[string]*os.File             // File handles for each log file.
+	fileAccessTimes map[string]time.Time            // Last access time for each file handle.
+	fileMutex       sync.Mutex                      // Mutex for synchronizing file access.
+	maxFileHandles  int                             // Maximum number of file handles.
+	AddSource       bool                            // Flag to add source file info in logs.
+	mode            Mode                            // Dispatch mode, asynchronous by default.
+	syncMutex       sync.Mutex                      // Mutex for serializing writes in synchronous mode.
+	writeErrors     atomic.Uint64                   // Number of failed file opens and writes.
+	lastWriteError  atomic.Pointer[error]           // Most recent file open or write error.
+	parent          *Logger                         // Root logger that child loggers write through.
+	name            string                          // Component name of a child logger.
+	groups          []string                        // Param groups of a child logger.
+	consoleAsFile   bool                            // Flag to format console output like file output.
+	emitVersion     bool                            // Flag to write a format version header to new files.
+	levelDests      map[LogLevel]Destination        // Per-level destination masks.
+	paramCollision  ParamCollision                  // Policy for params set more than once.
+	fileFormatter   LineFormatter                   // Optional formatter replacing the default file line layout.
+	tickerJitter    time.Duration                   // Maximum random delay added to each cleanup interval.
+	sink            *reconnectWriter                // Optional sink receiving formatted file lines.
+	framing         Framing                         // Record delimiting used for sinks.
+	levelCallbacks  map[LogLevel][]func(LogMessage) // Callbacks registered per level.
+	callbackMutex   sync.RWMutex                    // Mutex for synchronizing callback registration.
+	flushLevel      LogLevel                        // Minimum level of messages flushed to disk immediately.
+	colorMinLevel   LogLevel                        // Minimum level of messages rendered in color on the console.
+	maxParams       int                             // Maximum number of params rendered per message, 0 for no limit.
+	truncateConsole bool                            // Flag to truncate console lines to the terminal width.
+	consoleWidth    atomic.Int64                    // Current terminal width, 0 if unknown.
+	stopResizeWatch func()                          // Stops watching for terminal resizes.
+	flushEveryN     int                             // Number of messages per file between flushes, 0 to disable.
+	flushCounts     map[string]int                  // Messages written per file since the last flush.
+	location        *time.Location                  // Time zone of timestamps, nil for local time.
+	normalizeTimes  bool                            // Flag to convert time.Time params to the time zone.
+	sanitizeName    FileNameSanitizer               // Sanitizer applied to per-message file names.
+	hmacKey         []byte                          // Key for per-record HMACs, nil to disable.
+	consoleStream   ConsoleStream                   // Standard stream used for console output.
+	fileHealth      map[string]*fileHealth          // Failure state of files that failed recently.
+	degradedDrops   atomic.Uint64                   // Messages skipped because their file was degraded.
+	pending         atomic.Int64                    // Messages queued or being processed.
+	levelColors     map[LogLevel][]color.Attribute  // Custom console colors per level.
+	coalesceWindow  time.Duration                   // Window for coalescing file writes, 0 to disable.
+	batch           fileBatch                       // Records being coalesced by the processor.
+	sourceMinLevel  LogLevel                        // Minimum level of messages with source info.
+	fileLocking     bool                            // Flag to hold an advisory lock while writing.
+	fileFormat      FileFormat                      // Encoding of file output.
+	consoleLimit    *consoleLimiter                 // Optional console rate limiter.
+	levelAdjuster   func(LogMessage) LogLevel       // Optional hook recomputing a message's level.
+	tails           map[string][]*tailReader        // Tail readers per file name.
+	tailMutex       sync.Mutex                      // Mutex for synchronizing Tail readers.
+	snapshotFiles   map[string]bool                 // Files replaced by each record instead of appended to.
+	valueHandler    UnsupportedValueHandler         // Handler for param values formatters cannot render.
+	heartbeat       time.Duration                   // Interval of heartbeat messages, 0 to disable.
+	started         time.Time                       // Time the logger was created.
+	stop            chan struct{}                   // Closed when the logger is closed, to stop background routines.
+	stopOnce        sync.Once                       // Ensures stop is closed only once.
+	headerParts     []headerPart                    // Parsed header template, nil for the default layout.
+	closeFuncs      []func() error                  // Callbacks run by Close, in reverse registration order.
+	lineNumbers     bool                            // Flag to number the records of each file.
+	lineCounts      map[string]uint64               // Records numbered per file.
+	settingsMutex   sync.RWMutex                    // Mutex for settings changed at runtime.
+	skipEmpty       bool                            // Flag to drop messages without text and params.
+	routes          []fileRoute                     // Additional files with their own minimum levels.
+	lastFile        string                          // Name of the file written last.
+	lastHandle      *os.File                        // Handle of the file written last, nil if unknown.
+	durationFormat  DurationFormat                  // Rendering of duration params, or the formatter default.
+	collapse        bool                            // Flag to collapse consecutive identical messages.
+	lastLines       map[string]*lastLine            // Message written last per file, for collapsing.
+	collapseMutex   sync.Mutex                      // Mutex for synchronizing lastLines.
+	sendMutex       sync.RWMutex                    // Mutex held for reading while a message is dispatched.
+	closed          bool                            // Flag set once Close has started.
+	closeOnce       sync.Once                       // Ensures the logger is closed only once.
+	closeErr        error                           // Result of closing the logger.
+	discard         atomic.Bool                     // Flag to drop queued messages when closing without draining.
+	workers         sync.WaitGroup                  // Tracks the log processing goroutine.
+	maxFileSize     int64                           // Size at which files are rotated, 0 to disable.
+	maxBackups      int                             // Rotated generations kept per file, 0 for all.
+	fileSizes       map[string]int64                // Sizes of the open files, tracked for rotation.
+	dailyRotation   bool                            // Flag to write to a new dated file every day.
+	datedFiles      map[string]string               // Current dated file per log file name.
+	directSync      bool                            // Flag to open log files with O_SYNC.
+	uiCallback      func(LogLevel, string)          // Optional receiver of uncolored lines.
+	sidecarSize     int                             // Size above which values go to sidecar files, 0 to disable.
+	sidecarDir      string                          // Directory of sidecar files.
+	sidecarSeq      atomic.Uint64                   // Sequence number of the last sidecar file.
+	loggerName      string                          // Name prefixing the logger's own diagnostics.
+	markRecords     bool                            // Flag to frame text file records with markers.
+	recordStart     string                          // Marker starting each framed record.
+	recordCont      string                          // Prefix of the continuation lines of framed records.
+	fileWriter      io.Writer                       // Optional writer replacing log files.
+	consoleWriter   io.Writer                       // Optional writer replacing the console stream.
+	overflow        OverflowPolicy                  // Handling of messages logged while the channel is full.
+	dropped         atomic.Uint64                   // Messages dropped because the channel was full.
+	fields          map[string]interface{}          // Params added to every message of a child logger.
+	timeFormat      string                          // Layout of timestamps, empty for DefaultTimeFormat.
+	clock           func() time.Time                // Source of the current time, nil for the real clock.
+	errorHandler    func(error)                     // Optional receiver of the logger's own errors.
+	exitFunc        func(int)                       // Function exiting the process after Fatal, nil for os.Exit.
+	callbacks       sync.WaitGroup                  // Tracks running level callbacks.
+	writeBuffer     int                             // Size of the write buffer per file, 0 to disable.
+	bufferInterval  time.Duration                   // Interval between write buffer flushes.
+	buffers         map[string]*bufio.Writer        // Write buffers of the open files.
+	structuredJSON  bool                            // Flag to write file records as JSON objects.
+	fileLevels      map[string]LogLevel             // Minimum levels of single files, overriding FileLevel.
+	dirPerm         os.FileMode                     // Permission of directories created for log files.
+	ctxExtractor    ContextExtractor                // Optional source of params from contexts.
+	redactKeys      []string                        // Lowercase key patterns of params to mask.
+	colorOverride   *bool                           // Color setting forced with SetColorEnabled, nil to detect.
+	colors          bool                            // Flag to color console output.
+	errorColors     bool                            // Flag to color output of the error console.
+	errorConsole    io.Writer                       // Optional console writer for Warning and above.
+	reopenOnHangup  bool                            // Flag to reopen the log files on SIGHUP.
+	stopHangupWatch func()                          // Stops watching for SIGHUP.
+	hooks           []func(LogMessage)              // Hooks run for every processed message.
+	sampling        *sampler                        // Optional sampler of messages per level.
+	sampled         atomic.Uint64                   // Messages dropped by sampling.
+	dedup           *deduper                        // Optional suppressor of repeated messages.
+	writeManifest   bool                            // Flag to maintain manifests of rotated files.
+	writeSpans      map[string]writeSpan            // First and last write times per file, for manifests.
 }
 
 // LoggerOption defines a function type for logger configuration options.
@@ -71,7 +265,19 @@ func NewLogger(opts ...LoggerOption) (*Logger, error) {
 		AddSource:       false,                                    // Source file info is disabled by default.
 		fileHandles:     make(map[string]*os.File),
 		fileAccessTimes: make(map[string]time.Time),
+		flushCounts:     make(map[string]int),
+		lineCounts:      make(map[string]uint64),
+		fileSizes:       make(map[string]int64),
+		datedFiles:      make(map[string]string),
+		writeSpans:      make(map[string]writeSpan),
+		buffers:         make(map[string]*bufio.Writer),
+		fileHealth:      make(map[string]*fileHealth),
+		sanitizeName:    DefaultFileNameSanitizer,
 		maxFileHandles:  DefaultMaxFileHandles,
+		dirPerm:         DefaultDirPerm,
+		mode:            Asynchronous,
+		flushLevel:      LogLevelOff,
+		stop:            make(chan struct{}),
 	}
 
 	// Apply each configuration option to the logger
@@ -81,18 +287,42 @@ func NewLogger(opts ...LoggerOption) (*Logger, error) {
 		}
 	}
 
+	logger.started = logger.now()
+	logger.colors = logger.detectColors(logger.consoleOutput())
+	if logger.errorConsole != nil {
+		logger.errorColors = logger.detectColors(logger.errorConsole)
+	}
+
+	// Track the terminal width for console truncation
+	if logger.truncateConsole {
+		logger.startWidthWatcher()
+	}
+
+	// Start the heartbeat routine, which is also used in synchronous mode
+	if logger.heartbeat > 0 {
+		go logger.heartbeatLoop()
+	}
+
+	// Reopen the log files when an external tool has rotated them
+	if logger.reopenOnHangup {
+		logger.stopHangupWatch = watchHangup(func() { logger.ReopenFiles() })
+	}
+
+	// Write out buffered records regularly, in both modes
+	if logger.writeBuffer > 0 {
+		go logger.bufferFlushLoop()
+	}
+
+	// A synchronous logger writes from the caller and needs no other background routines.
+	if logger.mode == Synchronous {
+		return logger, nil
+	}
+
 	// Start the cleanup ticker routine.
-	go func() {
-		cleanupTicker := time.NewTicker(DefaultCleanupTicker)
-		for {
-			select {
-			case <-cleanupTicker.C:
-				logger.cleanupUnusedFileHandles()
-			}
-		}
-	}()
+	go logger.cleanupLoop()
 
 	// Start the log processing goroutine
+	logger.workers.Add(1)
 	go logger.processLogs()
 
 	return logger, nil
@@ -109,6 +339,19 @@ func SetBufferSize(size int) LoggerOption {
 	}
 }
 
+// SetMode sets the dispatch mode of the logger.
+// Synchronous mode trades throughput for determinism: log calls block until
+// the message has been written, and no background goroutines are started.
+func SetMode(mode Mode) LoggerOption {
+	return func(l *Logger) error {
+		if mode != Asynchronous && mode != Synchronous {
+			return fmt.Errorf("unknown mode: %d", mode)
+		}
+		l.mode = mode
+		return nil
+	}
+}
+
 // SetFileLevel sets the file log level.
 func SetFileLevel(level LogLevel) LoggerOption {
 	return func(l *Logger) error {
@@ -125,6 +368,72 @@ func SetConsoleLevel(level LogLevel) LoggerOption {
 	}
 }
 
+// SetFileLevelRuntime changes the file log level while the logger is in use,
+// e.g. to temporarily raise verbosity in production. Messages already queued
+// are filtered by the new level.
+// Unlike the FileLevel field, it is safe to call concurrently with logging.
+func (l *Logger) SetFileLevelRuntime(level LogLevel) {
+	root := l.root()
+	root.settingsMutex.Lock()
+	defer root.settingsMutex.Unlock()
+
+	root.FileLevel = level
+}
+
+// SetConsoleLevelRuntime changes the console log level while the logger is in use.
+// Unlike the ConsoleLevel field, it is safe to call concurrently with logging.
+func (l *Logger) SetConsoleLevelRuntime(level LogLevel) {
+	root := l.root()
+	root.settingsMutex.Lock()
+	defer root.settingsMutex.Unlock()
+
+	root.ConsoleLevel = level
+}
+
+// CurrentFileLevel returns the file log level.
+func (l *Logger) CurrentFileLevel() LogLevel {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	return root.FileLevel
+}
+
+// CurrentConsoleLevel returns the console log level.
+func (l *Logger) CurrentConsoleLevel() LogLevel {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	return root.ConsoleLevel
+}
+
+// SetLevelDestinations restricts the outputs used for messages of the given level.
+// The mask is applied in addition to the FileLevel and ConsoleLevel thresholds,
+// e.g. SetLevelDestinations(LogLevelDebug, FileOnly) keeps Debug off the console.
+func SetLevelDestinations(level LogLevel, dest Destination) LoggerOption {
+	return func(l *Logger) error {
+		if dest&^FileAndConsole != 0 {
+			return fmt.Errorf("unknown destination: %d", dest)
+		}
+		if l.levelDests == nil {
+			l.levelDests = make(map[LogLevel]Destination)
+		}
+		l.levelDests[level] = dest
+		return nil
+	}
+}
+
+// SetLevelAdjuster sets a function that recomputes the level of each message
+// after its options are applied, e.g. to raise requests with status >= 500 to
+// Error. The returned level is used for gating and formatting.
+func SetLevelAdjuster(adjust func(LogMessage) LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		l.levelAdjuster = adjust
+		return nil
+	}
+}
+
 // EnableSourceInfo enables or disables the logging of source file information.
 func EnableSourceInfo(enable bool) LoggerOption {
 	return func(l *Logger) error {
@@ -133,6 +442,51 @@ func EnableSourceInfo(enable bool) LoggerOption {
 	}
 }
 
+// SetSourceMinLevel limits source file information to messages at or above the level,
+// e.g. LogLevelError keeps Info and Debug lines short and skips the cost of
+// looking up their caller. It only applies when source info is enabled,
+// and defaults to every level.
+func SetSourceMinLevel(level LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		l.sourceMinLevel = level
+		return nil
+	}
+}
+
+// SetSkipEmptyMessages drops log calls whose message and params are both empty,
+// which are usually a bug and only add lines with a bare timestamp and level.
+// Empty messages with params, or with an event name, are still logged.
+func SetSkipEmptyMessages(skip bool) LoggerOption {
+	return func(l *Logger) error {
+		l.skipEmpty = skip
+		return nil
+	}
+}
+
+// SetDefaultFileRuntime redirects messages without their own log file to another
+// file while the logger is in use, e.g. once the configuration has been parsed.
+// Messages already queued keep the file they were logged with.
+// Unlike the DefaultFileName field, it is safe to call concurrently with logging.
+func (l *Logger) SetDefaultFileRuntime(name string) error {
+	if name == "" {
+		return fmt.Errorf("default file name must not be empty")
+	}
+	root := l.root()
+	root.settingsMutex.Lock()
+	defer root.settingsMutex.Unlock()
+
+	root.DefaultFileName = name
+	return nil
+}
+
+// defaultFile returns the default log file name.
+func (l *Logger) defaultFile() string {
+	l.settingsMutex.RLock()
+	defer l.settingsMutex.RUnlock()
+
+	return l.DefaultFileName
+}
+
 // SetDefaultFileName sets the default log file name.
 func SetDefaultFileName(fileName string) LoggerOption {
 	return func(l *Logger) error {
@@ -157,6 +511,47 @@ func EnableConsoleOutput(enable bool) LoggerOption {
 	}
 }
 
+// SetConsoleUsesFileFormat makes console output reuse the file format without colors.
+// This is useful when the console is captured by a log collector. With
+// FormatBinary files the console uses the text layout instead of binary records.
+func SetConsoleUsesFileFormat(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.consoleAsFile = enable
+		return nil
+	}
+}
+
+// SetEmitFormatVersion enables or disables writing a format version header
+// as the first line of every newly created log file, so parsers can detect
+// the layout of the lines that follow.
+func SetEmitFormatVersion(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.emitVersion = enable
+		return nil
+	}
+}
+
+// SetColorOnlyAtLevel applies console colors only to messages at or above the level,
+// e.g. LogLevelError highlights errors while everything else renders uncolored.
+func SetColorOnlyAtLevel(level LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		l.colorMinLevel = level
+		return nil
+	}
+}
+
+// SetConsoleStream sets the standard stream used for console output.
+// Stderr keeps stdout free for program output and piped data.
+func SetConsoleStream(stream ConsoleStream) LoggerOption {
+	return func(l *Logger) error {
+		if stream != Stdout && stream != Stderr {
+			return fmt.Errorf("unknown console stream: %d", stream)
+		}
+		l.consoleStream = stream
+		return nil
+	}
+}
+
 // SetParamFormatter sets the parameter formatting strategy for the logger.
 func SetParamFormatter(formatter ParamFormatter) LoggerOption {
 	return func(l *Logger) error {
@@ -165,6 +560,177 @@ func SetParamFormatter(formatter ParamFormatter) LoggerOption {
 	}
 }
 
+// SetDirectSync opens log files with O_SYNC, so every write reaches the disk
+// before it returns, without separate flush calls. This makes each write
+// considerably slower, often by orders of magnitude on spinning disks, so it
+// is meant for durability-critical logs. Buffered writes would defeat it, so
+// it should not be combined with write buffering. Platforms without O_SYNC
+// support ignore the flag.
+func SetDirectSync(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.directSync = enable
+		return nil
+	}
+}
+
+// SetFlushLevel makes messages at or above the level be flushed to disk
+// (fsync) right after they are written, so a crash just after an error
+// does not lose it. Lower levels are left to the operating system.
+// Flushing is disabled by default.
+func SetFlushLevel(level LogLevel) LoggerOption {
+	return func(l *Logger) error {
+		l.flushLevel = level
+		return nil
+	}
+}
+
+// SetFlushEveryN makes every file be flushed to disk (fsync) after n messages
+// have been written to it since its last flush, giving count-based durability
+// batches. Close flushes any remainder. Flushing is disabled by default.
+func SetFlushEveryN(n int) LoggerOption {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return fmt.Errorf("flushEveryN must be positive")
+		}
+		l.flushEveryN = n
+		return nil
+	}
+}
+
+// SetDirPerm sets the permission of the directories created for log files
+// whose parent directories do not exist yet. It defaults to DefaultDirPerm.
+func SetDirPerm(perm os.FileMode) LoggerOption {
+	return func(l *Logger) error {
+		if perm&^os.ModePerm != 0 {
+			return fmt.Errorf("invalid directory permission: %v", perm)
+		}
+		l.dirPerm = perm
+		return nil
+	}
+}
+
+// SetTimeLocation sets the time zone used for log timestamps.
+// Local time is used by default.
+func SetTimeLocation(loc *time.Location) LoggerOption {
+	return func(l *Logger) error {
+		if loc == nil {
+			return fmt.Errorf("time location must not be nil")
+		}
+		l.location = loc
+		return nil
+	}
+}
+
+// SetUTC makes log timestamps use UTC, or local time when disabled.
+// It replaces a time zone set with SetTimeLocation.
+func SetUTC(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		if enable {
+			l.location = time.UTC
+		} else {
+			l.location = nil
+		}
+		return nil
+	}
+}
+
+// SetTimeFormat sets the layout of log timestamps in file and console output,
+// e.g. time.RFC3339Nano. DefaultTimeFormat is used by default.
+func SetTimeFormat(layout string) LoggerOption {
+	return func(l *Logger) error {
+		if layout == "" {
+			return fmt.Errorf("time format must not be empty")
+		}
+		l.timeFormat = layout
+		return nil
+	}
+}
+
+// SetClock sets the function the logger reads the current time from, e.g. a
+// fixed time in tests. It is used for timestamps, daily rotation, the expiry
+// of idle file handles and the elapsed time of spans. Timeouts such as those
+// of WaitIdle and Shutdown always use the real clock.
+func SetClock(clock func() time.Time) LoggerOption {
+	return func(l *Logger) error {
+		if clock == nil {
+			return fmt.Errorf("clock must not be nil")
+		}
+		l.clock = clock
+		return nil
+	}
+}
+
+// SetNormalizeTimeParams enables or disables converting time.Time param values
+// to the logger's time zone before formatting, so that embedded timestamps
+// share the zone of the line timestamp.
+func SetNormalizeTimeParams(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		l.normalizeTimes = enable
+		return nil
+	}
+}
+
+// SetTickerJitter sets the maximum random delay added to each cleanup interval.
+// Jitter spreads the periodic work of many logger instances or processes
+// so that they do not all touch the disk at the same moment.
+func SetTickerJitter(maxJitter time.Duration) LoggerOption {
+	return func(l *Logger) error {
+		if maxJitter < 0 {
+			return fmt.Errorf("tickerJitter must not be negative")
+		}
+		l.tickerJitter = maxJitter
+		return nil
+	}
+}
+
+// SetParamCollision sets how a param key that is set more than once for
+// a single message is resolved. The default is ParamOverride.
+func SetParamCollision(policy ParamCollision) LoggerOption {
+	return func(l *Logger) error {
+		if policy < ParamOverride || policy > ParamReject {
+			return fmt.Errorf("unknown param collision policy: %d", policy)
+		}
+		l.paramCollision = policy
+		return nil
+	}
+}
+
+// SetFileFormatter sets a formatter that renders complete file log lines,
+// replacing the default "[time] LEVEL: message" layout.
+func SetFileFormatter(formatter LineFormatter) LoggerOption {
+	return func(l *Logger) error {
+		l.fileFormatter = formatter
+		return nil
+	}
+}
+
+// SetMaxParams limits the number of params rendered per message.
+// Params beyond the limit are replaced by a "...and K more" marker,
+// protecting log lines against callers passing huge param maps.
+func SetMaxParams(n int) LoggerOption {
+	return func(l *Logger) error {
+		if n <= 0 {
+			return fmt.Errorf("maxParams must be positive")
+		}
+		l.maxParams = n
+		return nil
+	}
+}
+
+// SetFileLocking enables or disables holding an exclusive advisory lock (flock)
+// on a log file while writing to it, so that several processes appending to
+// the same file do not interleave partial records. Other writers must use the
+// same locking to benefit. It returns an error on platforms without flock.
+func SetFileLocking(enable bool) LoggerOption {
+	return func(l *Logger) error {
+		if enable && !fileLockingSupported {
+			return fmt.Errorf("file locking is not supported on this platform")
+		}
+		l.fileLocking = enable
+		return nil
+	}
+}
+
 // SetMaxFileHandles sets the maximum number of file handles.
 func SetMaxFileHandles(maxHandles int) LoggerOption {
 	return func(l *Logger) error {
@@ -176,32 +742,306 @@ func SetMaxFileHandles(maxHandles int) LoggerOption {
 	}
 }
 
-func (l *Logger) Close() {
+// Close stops accepting messages, waits for queued messages to be written,
+// closes all open log files and then runs the callbacks registered with OnClose.
+// Messages logged after Close are dropped. It is safe to call Close more than once.
+// It returns the errors of those callbacks, joined.
+// Calling Close on a child logger closes the shared root logger.
+func (l *Logger) Close() error {
+	return l.root().close(true)
+}
+
+// close closes the logger, first waiting for queued messages if drain is set.
+// Only the first call closes the logger; later calls return the same error.
+func (l *Logger) close(drain bool) error {
+	l.closeOnce.Do(func() {
+		l.closeErr = l.closeLogger(drain)
+	})
+	return l.closeErr
+}
+
+// closeLogger stops accepting messages, lets the processor write or discard
+// the queued ones and then releases every resource of the logger.
+func (l *Logger) closeLogger(drain bool) error {
+	// Report suppressed repeats while messages are still accepted
+	if l.dedup != nil {
+		l.flushDedup()
+	}
+
+	// Wait for log calls in progress and reject new ones
+	l.sendMutex.Lock()
+	l.closed = true
+	l.sendMutex.Unlock()
+
+	// Let the processor finish the queue and exit. Without draining it only
+	// completes the message in progress, so nothing writes to closed files.
+	if l.mode == Asynchronous {
+		l.discard.Store(!drain)
+		close(l.LogChannel)
+		l.workers.Wait()
+	}
+
+	// Stop the background routines
+	l.stopOnce.Do(func() { close(l.stop) })
+
+	// Report repeats of the last messages before the files are closed
+	if l.collapse {
+		l.flushCollapsed()
+	}
+
 	l.fileMutex.Lock()
-	defer l.fileMutex.Unlock()
+	for filename, file := range l.fileHandles {
+		l.flushBufferLocked(filename)
 
-	for _, file := range l.fileHandles {
+		// Flush messages written since the last count-based flush
+		if l.flushCounts[filename] > 0 {
+			l.flushFileLocked(filename)
+		}
+		if file == nil {
+			continue
+		}
 		if err := file.Close(); err != nil {
-			fmt.Printf("Failed to close log file: %v", err)
+			l.diagf("Failed to close log file: %w", err)
+		}
+	}
+	// Forget the closed handles so nothing writes to them
+	l.fileHandles = make(map[string]*os.File)
+	l.fileAccessTimes = make(map[string]time.Time)
+	l.buffers = make(map[string]*bufio.Writer)
+	l.lastHandle = nil
+	l.fileMutex.Unlock()
+
+	if l.consoleLimit != nil {
+		if note := l.consoleLimit.pendingNote(); note != "" {
+			fmt.Fprintln(l.consoleOutput(), note)
+		}
+	}
+
+	if l.stopResizeWatch != nil {
+		l.stopResizeWatch()
+		l.stopResizeWatch = nil
+	}
+
+	if l.stopHangupWatch != nil {
+		l.stopHangupWatch()
+		l.stopHangupWatch = nil
+	}
+
+	if l.sink != nil {
+		if err := l.sink.Close(); err != nil {
+			l.diagf("Failed to close log sink: %w", err)
+		}
+	}
+
+	l.closeTails()
+
+	return l.runCloseCallbacks()
+}
+
+// now returns the current time of the logger's clock.
+func (l *Logger) now() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+	return time.Now()
+}
+
+// formatTime formats a log timestamp with the configured layout.
+func (l *Logger) formatTime(t time.Time) string {
+	if l.timeFormat == "" {
+		return t.Format(DefaultTimeFormat)
+	}
+	return t.Format(l.timeFormat)
+}
+
+// timeLocation returns the time zone used for log timestamps.
+func (l *Logger) timeLocation() *time.Location {
+	if l.location != nil {
+		return l.location
+	}
+	return time.Local
+}
+
+// WaitIdle waits until every queued message has been processed, or until the timeout expires.
+// It reports whether the logger became idle. Unlike Shutdown it does not close anything,
+// so it can be used to check log files at a checkpoint or in tests.
+func (l *Logger) WaitIdle(timeout time.Duration) bool {
+	l = l.root()
+	deadline := time.Now().Add(timeout)
+	for l.pending.Load() > 0 {
+		if !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// ShutdownProgress reports the number of queued messages still to be written
+// and the time left before the Shutdown deadline.
+type ShutdownProgress func(remaining int, timeLeft time.Duration)
+
+// Shutdown waits up to timeout for queued messages to be written and then closes the logger.
+// If progress is not nil, it is called at regular intervals while messages remain,
+// and once more when the deadline is close, so slow shutdowns are visible.
+// It returns an error if messages were still queued when the deadline passed,
+// joined with the errors of the OnClose callbacks. The remaining messages are
+// then dropped, but the message being written is completed first.
+func (l *Logger) Shutdown(timeout time.Duration, progress ShutdownProgress) error {
+	l = l.root()
+	deadline := time.Now().Add(timeout)
+	lastReport := time.Now()
+	warned := false
+
+	for l.pending.Load() > 0 {
+		timeLeft := time.Until(deadline)
+		if timeLeft <= 0 {
+			remaining := l.pending.Load()
+			err := fmt.Errorf("shutdown timed out with %d messages pending", remaining)
+			return errors.Join(err, l.close(false))
 		}
+
+		if progress != nil {
+			nearDeadline := timeLeft <= DefaultShutdownProgressInterval
+			if time.Since(lastReport) >= DefaultShutdownProgressInterval || (nearDeadline && !warned) {
+				progress(int(l.pending.Load()), timeLeft)
+				lastReport = time.Now()
+				warned = warned || nearDeadline
+			}
+		}
+
+		time.Sleep(10 * time.Millisecond)
 	}
+
+	return l.close(true)
+}
+
+// Options binds a set of log options into a single reusable option,
+// e.g. opts := logger.Options(SetLogFile("jobs.log"), SetLogParams(p))
+// followed by logger.Info("started", opts).
+func (l *Logger) Options(opts ...LogOption) LogOption {
+	return CombineOptions(opts...)
+}
+
+// Enabled reports whether a message at the given level would be written
+// to the file or the console output, e.g. to skip computing expensive params.
+func (l *Logger) Enabled(level LogLevel) bool {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	return root.fileEnabled(level) || root.consoleEnabled(level)
+}
+
+// IsFileLevelEnabled reports whether a message at the given level would be
+// written to a file, taking routes and the levels of single files into account.
+func (l *Logger) IsFileLevelEnabled(level LogLevel) bool {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	return root.fileEnabled(level)
+}
+
+// IsConsoleLevelEnabled reports whether a message at the given level would be
+// written to the console.
+func (l *Logger) IsConsoleLevelEnabled(level LogLevel) bool {
+	root := l.root()
+	root.settingsMutex.RLock()
+	defer root.settingsMutex.RUnlock()
+
+	return root.consoleEnabled(level)
+}
+
+// fileEnabled reports whether file output is enabled for the level.
+// The caller must hold settingsMutex for reading.
+func (l *Logger) fileEnabled(level LogLevel) bool {
+	return l.OutputToFile && l.wantsAnyFile(level)
+}
+
+// consoleEnabled reports whether console output is enabled for the level.
+// The caller must hold settingsMutex for reading.
+func (l *Logger) consoleEnabled(level LogLevel) bool {
+	return l.OutputToConsole && l.wantsConsole(level)
 }
 
 // log is an internal method to log a message with given options.
 // It formats the message based on the log level, and sends it to the LogChannel.
 // This method is used by public methods like Debug, Info, Warning, Error.
+// Child loggers format with their own context but write through the root logger.
 func (l *Logger) log(level LogLevel, message string, opts ...LogOption) {
-	// If the log level is not sufficient for file or console output, skip processing
-	if level < l.FileLevel && level < l.ConsoleLevel {
+	root := l.root()
+
+	// Build under the settings lock, but release it before the message is
+	// queued so that Apply cannot deadlock with a full channel
+	root.settingsMutex.RLock()
+	if root.sampling != nil && root.sampledOut(level) {
+		root.settingsMutex.RUnlock()
+		return
+	}
+	logMsg, ok := l.buildMessage(level, message, opts)
+	root.settingsMutex.RUnlock()
+	if !ok {
 		return
 	}
 
+	// Suppress repeats of the current message
+	if root.dedup != nil && root.dedupRepeat(logMsg) {
+		return
+	}
+	root.dispatch(logMsg)
+}
+
+// dispatch writes a built message directly in synchronous mode, or queues it.
+// It must be called on the root logger.
+func (l *Logger) dispatch(logMsg LogMessage) {
+	// Drop messages logged after Close
+	l.sendMutex.RLock()
+	defer l.sendMutex.RUnlock()
+	if l.closed {
+		return
+	}
+
+	// Write the message directly in synchronous mode
+	if l.mode == Synchronous {
+		l.syncMutex.Lock()
+		l.processMessage(logMsg)
+		l.syncMutex.Unlock()
+		return
+	}
+
+	// Send the message to the LogChannel
+	l.enqueue(logMsg)
+}
+
+// buildMessage prepares a log message for processing. It reports false if the
+// message is not logged. The caller must hold settingsMutex for reading.
+func (l *Logger) buildMessage(level LogLevel, message string, opts []LogOption) (LogMessage, bool) {
+	root := l.root()
+
+	toFile := root.wantsAnyFile(level)
+	toConsole := root.wantsConsole(level)
+
+	// If the log level is not sufficient for file or console output, skip processing.
+	// A level adjuster may still raise the level and an option may still force
+	// output to stderr, so gating waits until they have run.
+	if !toFile && !toConsole && root.levelAdjuster == nil && len(opts) == 0 {
+		return LogMessage{}, false
+	}
+
 	// Prepare the log message
 	logMsg := LogMessage{
-		Level:   level,
-		Message: message,
-		File:    l.DefaultFileName, // Default log file
-		Params:  make(map[string]interface{}),
+		Level:     level,
+		Message:   message,
+		File:      root.DefaultFileName, // Default log file
+		Component: l.name,
+		collision: root.paramCollision,
+		owner:     root,
+	}
+
+	// Add the fields bound to a child logger before the per-call params
+	for key, value := range l.fields {
+		logMsg.setParam(key, value)
 	}
 
 	// Apply each option to the LogMessage
@@ -209,42 +1049,119 @@ func (l *Logger) log(level LogLevel, message string, opts ...LogOption) {
 		opt(&logMsg)
 	}
 
-	// Format the current time
-	timestamp := time.Now().Format("2006/01/02 15:04:05")
+	// Recompute the level and gate on the adjusted level
+	if root.levelAdjuster != nil {
+		level = root.levelAdjuster(logMsg)
+		logMsg.Level = level
+		toFile = root.wantsAnyFile(level)
+		toConsole = root.wantsConsole(level)
+	}
+	if !toFile && !toConsole && !logMsg.alsoStderr {
+		return LogMessage{}, false
+	}
 
-	// Format log parameters
-	formattedParams := l.paramFormatter(logMsg.Params)
+	// Drop messages with duplicate params under ParamReject
+	if logMsg.duplicateKey != "" {
+		root.diagf("Dropped log message %q: duplicate param key %q", logMsg.Message, logMsg.duplicateKey)
+		return LogMessage{}, false
+	}
+
+	// Drop empty messages before anything is formatted or queued
+	if root.skipEmpty && logMsg.Message == "" && len(logMsg.Params) == 0 && logMsg.Event == "" {
+		return LogMessage{}, false
+	}
 
-	var sourceInfo, fileMessage, consoleMessage string
+	// Nest the params under the groups of a child logger
+	logMsg.Params = groupParams(logMsg.Params, l.groups)
 
-	// Prepare source information
-	if l.AddSource {
-		callerFile, callerLine := getCallerInfo()
-		sourceInfo = fmt.Sprintf("[%s:%d]", filepath.Base(callerFile), callerLine)
+	// Mask sensitive values before they are written anywhere
+	if root.redactKeys != nil {
+		logMsg.Params, _ = root.redactParams(logMsg.Params)
 	}
 
-	// Prepare the log message for file output
-	if level >= l.FileLevel {
-		fileMessage = l.prepareFileMessage(timestamp, sourceInfo, level, logMsg.Message, formattedParams)
+	// Move large values to sidecar files before they are formatted
+	if root.sidecarSize > 0 {
+		logMsg.Params, _ = root.moveLargeValues(logMsg.Params, "")
 	}
 
-	// Prepare the log message for console output
-	if level >= l.ConsoleLevel {
-		consoleMessage = l.prepareConsoleMessage(timestamp, sourceInfo, level, logMsg.Message, formattedParams)
+	// Record the current time, unless the message carries its own timestamp
+	if logMsg.Time.IsZero() {
+		logMsg.Time = root.now()
 	}
+	logMsg.Time = logMsg.Time.In(root.timeLocation())
 
-	// Send the message to the LogChannel
-	l.LogChannel <- LogMessage{
-		Level:          level,
-		FileMessage:    fileMessage,
-		ConsoleMessage: consoleMessage,
-		File:           logMsg.File,
+	// Record the source, which is only known on the caller's goroutine
+	if root.AddSource && level.AtLeast(root.sourceMinLevel) {
+		var callerFile string
+		var callerLine int
+		if logMsg.pc != 0 {
+			callerFile, callerLine = getFrameInfo(logMsg.pc)
+		} else {
+			callerFile, callerLine = getCallerInfo()
+		}
+		logMsg.Source = fmt.Sprintf("%s:%d", filepath.Base(callerFile), callerLine)
+	}
+
+	return logMsg, true
+}
+
+// formatMessage renders the file and console output of a message from its
+// structured fields. It runs when the message is processed, so messages wait
+// in the LogChannel unformatted. The caller must hold settingsMutex for reading.
+func (l *Logger) formatMessage(logMsg LogMessage) LogMessage {
+	toFile := l.wantsAnyFile(logMsg.Level)
+	toConsole := l.wantsConsole(logMsg.Level) || logMsg.alsoStderr
+	if !toFile && !toConsole {
+		return logMsg
+	}
+
+	timestamp := l.formatTime(logMsg.Time)
+	formattedParams := l.paramFormatter(l.renderParams(logMsg.displayParams()))
+	var sourceInfo string
+	if logMsg.Source != "" {
+		sourceInfo = "[" + logMsg.Source + "]"
+	}
+
+	// Prepare the log message for file output
+	if toFile {
+		logMsg.FileMessage = l.prepareFileMessage(logMsg, timestamp, sourceInfo, formattedParams)
+	}
+
+	// Prepare the log message for console output, which is also used for stderr
+	if toConsole && l.consoleAsFile {
+		logMsg.ConsoleMessage = l.prepareTextFileMessage(logMsg, timestamp, sourceInfo, formattedParams)
+	} else if toConsole {
+		logMsg.ConsoleMessage = l.prepareConsoleMessage(timestamp, sourceInfo, logMsg.Level, logMsg.displayMessage(), formattedParams)
 	}
+	return logMsg
 }
 
 // prepareFileMessage formats the log message for file output.
-func (l *Logger) prepareFileMessage(timestamp, sourceInfo string, level LogLevel, message, formattedParams string) string {
-	fileMessage := fmt.Sprintf("[%s]%s %s: %s", timestamp, sourceInfo, level.String(), message)
+func (l *Logger) prepareFileMessage(logMsg LogMessage, timestamp, sourceInfo, formattedParams string) string {
+	if l.fileFormat == FormatBinary {
+		logMsg.Params = l.renderParams(logMsg.Params)
+		return string(EncodeBinaryRecord(logMsg))
+	}
+	return l.prepareTextFileMessage(logMsg, timestamp, sourceInfo, formattedParams)
+}
+
+// prepareTextFileMessage formats the log message in the text layout of file output,
+// which is also used for the console when it reuses the file format.
+func (l *Logger) prepareTextFileMessage(logMsg LogMessage, timestamp, sourceInfo, formattedParams string) string {
+	if l.structuredJSON {
+		logMsg.Params = l.renderParams(logMsg.Params)
+		return l.formatStructuredJSON(logMsg)
+	}
+	if l.fileFormatter != nil {
+		logMsg.Params = l.renderParams(logMsg.Params)
+		return l.fileFormatter(logMsg)
+	}
+	var fileMessage string
+	if l.headerParts != nil {
+		fileMessage = l.renderHeader(logMsg, timestamp)
+	} else {
+		fileMessage = fmt.Sprintf("[%s]%s %s: %s", timestamp, sourceInfo, logMsg.Level.String(), logMsg.displayMessage())
+	}
 	if formattedParams != "" {
 		fileMessage += "\n" + formattedParams
 	}
@@ -253,8 +1170,17 @@ func (l *Logger) prepareFileMessage(timestamp, sourceInfo string, level LogLevel
 
 // prepareConsoleMessage formats the log message for console output with color.
 func (l *Logger) prepareConsoleMessage(timestamp, sourceInfo string, level LogLevel, message, formattedParams string) string {
-	coloredLevel := formatLogLevel(level.String(), level, true) // Colored and bold level
-	coloredMessage := formatLogLevel(message, level, false)     // Colored message without bold
+	// Render plain text without colors or below the coloring threshold
+	if !l.colorsFor(level) || level.Below(l.colorMinLevel) {
+		consoleMessage := fmt.Sprintf("[%s]%s %s: %s", timestamp, sourceInfo, level.String(), message)
+		if formattedParams != "" {
+			consoleMessage += "\n" + formattedParams
+		}
+		return consoleMessage
+	}
+
+	coloredLevel := l.colorize(level.String(), level, true) // Colored and bold level
+	coloredMessage := l.colorize(message, level, false)     // Colored message without bold
 	consoleMessage := fmt.Sprintf("[%s]%s %s: %s", timestamp, sourceInfo, coloredLevel, coloredMessage)
 	if formattedParams != "" {
 		consoleMessage += "\n" + formatParamsWithColor(formattedParams)
@@ -287,7 +1213,15 @@ func (l *Logger) Error(message string, opts ...LogOption) {
 	l.log(LogLevelError, message, opts...)
 }
 
-// Fatal logs a message at the Fatal level.
+// Event logs a structured event at the Info level.
+// The event name is used both as the message and as the "event" field.
+func (l *Logger) Event(name string, opts ...LogOption) {
+	l.log(LogLevelInfo, name, append([]LogOption{SetEventName(name)}, opts...)...)
+}
+
+// Fatal logs a message at the Fatal level, then closes the logger so that the
+// message is written and flushed, and exits the process with status 1.
 func (l *Logger) Fatal(message string, opts ...LogOption) {
 	l.log(LogLevelFatal, message, opts...)
+	l.exitFatal()
 }
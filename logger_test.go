@@ -0,0 +1,594 @@
+package asynclog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// newTestLogger creates a logger writing its default file into a temporary
+// directory, without console output, and closes it when the test ends.
+// It returns the logger and the path of the default file.
+func newTestLogger(t testing.TB, opts ...LoggerOption) (*Logger, string) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "app.log")
+	base := []LoggerOption{SetDefaultFileName(file), EnableConsoleOutput(false)}
+	l, err := NewLogger(append(base, opts...)...)
+	if err != nil {
+		t.Fatalf("NewLogger: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l, file
+}
+
+// readFile returns the content of a file, or "" if it does not exist.
+func readFile(t testing.TB, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(data)
+}
+
+func TestSynchronousMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		level   LogLevel
+		want    string
+	}{
+		{"info", "started", LogLevelInfo, "INFO: started"},
+		{"error", "failed", LogLevelError, "ERROR: failed"},
+		{"below file level", "details", LogLevelDebug, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			l, file := newTestLogger(t, SetMode(Synchronous))
+			if after := runtime.NumGoroutine(); after > before {
+				t.Errorf("NewLogger started %d goroutines in synchronous mode", after-before)
+			}
+
+			l.log(tt.level, tt.message)
+			// The message is written before the log call returns
+			got := readFile(t, file)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("file = %q, want empty", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSynchronousModeConcurrentCallers(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous))
+
+	const goroutines, perGoroutine = 8, 50
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				l.Info("message")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := strings.Count(readFile(t, file), "INFO: message\n"); got != goroutines*perGoroutine {
+		t.Errorf("file has %d lines, want %d", got, goroutines*perGoroutine)
+	}
+}
+
+func TestSetModeRejectsUnknownMode(t *testing.T) {
+	if _, err := NewLogger(SetMode(Mode(42))); err == nil {
+		t.Error("NewLogger accepted an unknown mode")
+	}
+}
+
+func TestWaitIdle(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocked bool
+		want    bool
+	}{
+		{"idle once the queue is written", false, true},
+		{"times out while the processor is busy", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t)
+			release := make(chan struct{})
+			if tt.blocked {
+				l.AddHook(func(LogMessage) { <-release })
+			}
+			defer close(release)
+
+			const n = 50
+			for i := 0; i < n; i++ {
+				l.Info("message")
+			}
+
+			if got := l.WaitIdle(100 * time.Millisecond); got != tt.want {
+				t.Fatalf("WaitIdle = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			if got := strings.Count(readFile(t, file), "INFO: message\n"); got != n {
+				t.Errorf("file has %d lines after WaitIdle, want %d", got, n)
+			}
+		})
+	}
+}
+
+func TestSetDefaultFileRuntime(t *testing.T) {
+	tests := []struct {
+		name string
+		mode Mode
+	}{
+		{"asynchronous", Asynchronous},
+		{"synchronous", Synchronous},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, first := newTestLogger(t, SetMode(tt.mode))
+			second := filepath.Join(filepath.Dir(first), "other.log")
+
+			// Toggle the default file while several goroutines log, for -race
+			const goroutines, perGoroutine = 4, 100
+			var wg sync.WaitGroup
+			for g := 0; g < goroutines; g++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perGoroutine; i++ {
+						l.Info("message")
+					}
+				}()
+			}
+			for i := 0; i < 50; i++ {
+				name := first
+				if i%2 == 0 {
+					name = second
+				}
+				if err := l.SetDefaultFileRuntime(name); err != nil {
+					t.Fatalf("SetDefaultFileRuntime: %v", err)
+				}
+			}
+			wg.Wait()
+			l.Close()
+
+			total := strings.Count(readFile(t, first), "\n") + strings.Count(readFile(t, second), "\n")
+			if total != goroutines*perGoroutine {
+				t.Errorf("files hold %d lines, want %d", total, goroutines*perGoroutine)
+			}
+		})
+	}
+}
+
+func TestSetDefaultFileRuntimeRejectsEmptyName(t *testing.T) {
+	l, _ := newTestLogger(t)
+	if err := l.SetDefaultFileRuntime(""); err == nil {
+		t.Error("SetDefaultFileRuntime accepted an empty name")
+	}
+}
+
+func TestCloseWritesQueuedMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+	}{
+		{"single message", 1},
+		{"more messages than the buffer holds", 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetBufferSize(16))
+			for i := 0; i < tt.n; i++ {
+				l.Info("message")
+			}
+			if err := l.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if got := strings.Count(readFile(t, file), "INFO: message\n"); got != tt.n {
+				t.Errorf("file has %d lines after Close, want %d", got, tt.n)
+			}
+		})
+	}
+}
+
+func TestShutdownTimeoutWaitsForProcessor(t *testing.T) {
+	l, _ := newTestLogger(t)
+	release := make(chan struct{})
+	var processed sync.WaitGroup
+	processed.Add(1)
+	l.AddHook(func(m LogMessage) {
+		if m.Message == "first" {
+			processed.Done()
+			<-release
+		}
+	})
+	l.Info("first")
+	l.Info("skipped")
+	processed.Wait()
+
+	done := make(chan error, 1)
+	go func() { done <- l.Shutdown(10*time.Millisecond, nil) }()
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned while the processor was still writing")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err == nil {
+		t.Error("Shutdown reported no timeout")
+	}
+}
+
+func TestRuntimeLevels(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileLevel LogLevel
+		wantDebug bool
+	}{
+		{"raised verbosity", LogLevelDebug, true},
+		{"lowered verbosity", LogLevelError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetFileLevel(LogLevelInfo))
+			l.SetFileLevelRuntime(tt.fileLevel)
+			l.SetConsoleLevelRuntime(tt.fileLevel)
+			if got := l.CurrentFileLevel(); got != tt.fileLevel {
+				t.Errorf("CurrentFileLevel = %v, want %v", got, tt.fileLevel)
+			}
+			if got := l.CurrentConsoleLevel(); got != tt.fileLevel {
+				t.Errorf("CurrentConsoleLevel = %v, want %v", got, tt.fileLevel)
+			}
+
+			l.Debug("details")
+			if got := strings.Contains(readFile(t, file), "DEBUG: details"); got != tt.wantDebug {
+				t.Errorf("debug message written = %v, want %v", got, tt.wantDebug)
+			}
+		})
+	}
+}
+
+func TestRuntimeLevelsConcurrentWithLogging(t *testing.T) {
+	var console syncBuffer
+	l, _ := newTestLogger(t, EnableConsoleOutput(true), SetConsoleWriter(&console))
+	child := l.WithFields(map[string]interface{}{"service": "api"})
+
+	// Flip the levels while messages are logged and processed, for -race
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		levels := []LogLevel{LogLevelDebug, LogLevelError}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			l.SetFileLevelRuntime(levels[i%2])
+			child.SetConsoleLevelRuntime(levels[(i+1)%2])
+		}
+	}()
+	for i := 0; i < 500; i++ {
+		l.Debug("details")
+		child.Info("message")
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestTimeFormat(t *testing.T) {
+	zone := time.FixedZone("CEST", 2*60*60)
+	at := time.Date(2024, 6, 1, 14, 0, 0, 123456789, zone)
+	tests := []struct {
+		name string
+		opts []LoggerOption
+		want string
+	}{
+		{"default", nil, "[" + at.Local().Format(DefaultTimeFormat) + "] INFO: started"},
+		{"RFC3339Nano in UTC", []LoggerOption{SetTimeFormat(time.RFC3339Nano), SetUTC(true)},
+			"[2024-06-01T12:00:00.123456789Z] INFO: started"},
+		{"time location", []LoggerOption{SetTimeFormat(time.RFC3339), SetTimeLocation(zone)},
+			"[2024-06-01T14:00:00+02:00] INFO: started"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var console bytes.Buffer
+			opts := append([]LoggerOption{SetMode(Synchronous), SetClock(func() time.Time { return at }),
+				EnableConsoleOutput(true), SetConsoleWriter(&console)}, tt.opts...)
+			l, file := newTestLogger(t, opts...)
+
+			l.Info("started")
+
+			if got := readFile(t, file); !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+			if got := console.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("console = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeFormatRFC3339NanoUTCPattern(t *testing.T) {
+	l, file := newTestLogger(t, SetMode(Synchronous), SetTimeFormat(time.RFC3339Nano), SetUTC(true))
+	l.Info("started")
+
+	pattern := regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d{1,9})?Z\] INFO: started\n$`)
+	if got := readFile(t, file); !pattern.MatchString(got) {
+		t.Errorf("file = %q, want it to match %s", got, pattern)
+	}
+}
+
+func TestSetClock(t *testing.T) {
+	frozen := time.Date(2024, 6, 1, 12, 30, 45, 0, time.UTC)
+	tests := []struct {
+		name    string
+		message string
+		opts    []LogOption
+		want    string
+	}{
+		{"frozen timestamp", "started", nil, "[2024/06/01 12:30:45] INFO: started\n"},
+		{"explicit timestamp wins", "replayed",
+			[]LogOption{SetTimestamp(time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC))},
+			"[2023/01/02 03:04:05] INFO: replayed\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetUTC(true),
+				SetClock(func() time.Time { return frozen }))
+
+			l.Info(tt.message, tt.opts...)
+
+			if got := readFile(t, file); got != tt.want {
+				t.Errorf("file = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetClockExpiresIdleHandles(t *testing.T) {
+	clock := &testClock{now: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l, file := newTestLogger(t, SetMode(Synchronous), SetClock(clock.Now))
+	l.Info("started")
+
+	// The handle expires by the logger's clock, not the real one
+	clock.Add(DefaultUnusedFileHandleThreshold - time.Second)
+	l.cleanupUnusedFileHandles()
+	if !l.hasFileHandle(file) {
+		t.Fatal("handle closed before it was idle long enough")
+	}
+	clock.Add(2 * time.Second)
+	l.cleanupUnusedFileHandles()
+	if l.hasFileHandle(file) {
+		t.Error("idle handle was not closed")
+	}
+}
+
+// hasFileHandle reports whether the logger holds an open handle of the file.
+func (l *Logger) hasFileHandle(filename string) bool {
+	l.fileMutex.Lock()
+	defer l.fileMutex.Unlock()
+	return l.fileHandles[filename] != nil
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       LogLevel
+		wantFile    bool
+		wantConsole bool
+	}{
+		{"below both levels", LogLevelDebug, false, false},
+		{"at the file level", LogLevelInfo, true, false},
+		{"just below the console level", LogLevelInfo, true, false},
+		{"at the console level", LogLevelWarning, true, true},
+		{"above both levels", LogLevelFatal, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, SetFileLevel(LogLevelInfo), SetConsoleLevel(LogLevelWarning),
+				EnableConsoleOutput(true), SetConsoleWriter(&bytes.Buffer{}))
+
+			if got := l.IsFileLevelEnabled(tt.level); got != tt.wantFile {
+				t.Errorf("IsFileLevelEnabled(%v) = %v, want %v", tt.level, got, tt.wantFile)
+			}
+			if got := l.IsConsoleLevelEnabled(tt.level); got != tt.wantConsole {
+				t.Errorf("IsConsoleLevelEnabled(%v) = %v, want %v", tt.level, got, tt.wantConsole)
+			}
+			if got := l.Enabled(tt.level); got != (tt.wantFile || tt.wantConsole) {
+				t.Errorf("Enabled(%v) = %v, want %v", tt.level, got, tt.wantFile || tt.wantConsole)
+			}
+		})
+	}
+}
+
+func TestEnabledOutputsAndRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []LoggerOption
+		level LogLevel
+		want  bool
+	}{
+		{"file output disabled", []LoggerOption{EnableFileOutput(false)}, LogLevelError, false},
+		{"file level off", []LoggerOption{SetFileLevel(LogLevelOff)}, LogLevelFatal, false},
+		{"route below the file level", []LoggerOption{RouteToFile("debug.log", LogLevelDebug)}, LogLevelDebug, true},
+		{"file with a lower level", []LoggerOption{SetFileLevelFor("debug.log", LogLevelDebug)}, LogLevelDebug, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, _ := newTestLogger(t, append([]LoggerOption{SetFileLevel(LogLevelInfo)}, tt.opts...)...)
+			if got := l.Enabled(tt.level); got != tt.want {
+				t.Errorf("Enabled(%v) = %v, want %v", tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelComparison(t *testing.T) {
+	tests := []struct {
+		level, other LogLevel
+		atLeast      bool
+	}{
+		{LogLevelInfo, LogLevelInfo, true},
+		{LogLevelError, LogLevelWarning, true},
+		{LogLevelDebug, LogLevelInfo, false},
+		{MaxLevel, MinLevel, true},
+		{MinLevel, MaxLevel, false},
+		{LogLevelFatal, LogLevelOff, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String()+"/"+tt.other.String(), func(t *testing.T) {
+			if got := tt.level.AtLeast(tt.other); got != tt.atLeast {
+				t.Errorf("%v.AtLeast(%v) = %v, want %v", tt.level, tt.other, got, tt.atLeast)
+			}
+			if got := tt.level.Below(tt.other); got == tt.atLeast {
+				t.Errorf("%v.Below(%v) = %v, want %v", tt.level, tt.other, got, !tt.atLeast)
+			}
+		})
+	}
+	if MinLevel != LogLevelTrace || MaxLevel != LogLevelFatal {
+		t.Errorf("MinLevel, MaxLevel = %v, %v, want TRACE, FATAL", MinLevel, MaxLevel)
+	}
+}
+
+func TestSetSourceMinLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []LoggerOption
+		withSource []bool // For Info, Warning and Error.
+	}{
+		{"every level by default", []LoggerOption{EnableSourceInfo(true)}, []bool{true, true, true}},
+		{"from warning", []LoggerOption{EnableSourceInfo(true), SetSourceMinLevel(LogLevelWarning)}, []bool{false, true, true}},
+		{"source info disabled", []LoggerOption{SetSourceMinLevel(LogLevelWarning)}, []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, append([]LoggerOption{SetMode(Synchronous)}, tt.opts...)...)
+
+			l.Info("info")
+			l.Warning("warning")
+			l.Error("error")
+
+			lines := strings.Split(strings.TrimSuffix(readFile(t, file), "\n"), "\n")
+			if len(lines) != len(tt.withSource) {
+				t.Fatalf("got %d lines, want %d: %q", len(lines), len(tt.withSource), lines)
+			}
+			for i, line := range lines {
+				if got := strings.Contains(line, "logger_test.go:"); got != tt.withSource[i] {
+					t.Errorf("line %q has source info = %v, want %v", line, got, tt.withSource[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSetLevelAdjuster(t *testing.T) {
+	adjust := func(m LogMessage) LogLevel {
+		if took, ok := m.Params["took"].(time.Duration); ok && took > time.Second {
+			return LogLevelWarning
+		}
+		if m.Params["noisy"] == true {
+			return LogLevelDebug
+		}
+		return m.Level
+	}
+	tests := []struct {
+		name   string
+		log    func(l *Logger)
+		want   string
+		silent bool
+	}{
+		{"unchanged", func(l *Logger) {
+			l.Info("query", SetLogParams(map[string]interface{}{"took": 10 * time.Millisecond}))
+		}, "INFO: query", false},
+		{"raised", func(l *Logger) {
+			l.Info("query", SetLogParams(map[string]interface{}{"took": 2 * time.Second}))
+		}, "WARNING: query", false},
+		{"raised past the level gate", func(l *Logger) {
+			l.Debug("query", SetLogParams(map[string]interface{}{"took": 2 * time.Second}))
+		}, "WARNING: query", false},
+		{"lowered below the level gate", func(l *Logger) {
+			l.Info("poll", SetLogParams(map[string]interface{}{"noisy": true}))
+		}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetLevelAdjuster(adjust))
+
+			tt.log(l)
+
+			got := readFile(t, file)
+			if tt.silent && got != "" {
+				t.Errorf("file = %q, want nothing", got)
+			}
+			if !tt.silent && !strings.Contains(got, tt.want) {
+				t.Errorf("file = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetSkipEmptyMessages(t *testing.T) {
+	tests := []struct {
+		name    string
+		skip    bool
+		opts    []LogOption
+		written bool
+	}{
+		{"empty kept by default", false, nil, true},
+		{"empty skipped", true, nil, false},
+		{"empty with params kept", true, []LogOption{SetLogParams(map[string]interface{}{"id": 7})}, true},
+		{"empty with event kept", true, []LogOption{SetEventName("user.login")}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, file := newTestLogger(t, SetMode(Synchronous), SetSkipEmptyMessages(tt.skip))
+
+			l.Info("", tt.opts...)
+
+			if got := readFile(t, file); (got != "") != tt.written {
+				t.Errorf("file = %q, want written = %v", got, tt.written)
+			}
+		})
+	}
+}